@@ -0,0 +1,389 @@
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AdminHandler is an http.Handler exposing loggers to change
+// their verbosity and flags at runtime and to get handler health:
+//
+//	GET  /              list all registered loggers
+//	GET  /{name}        get a logger
+//	PUT  /{name}        update a logger with a JSON body as:
+//	                    {"verbosity": "debug", "flags": ["timestamp"], "duration": "5m",
+//	                     "vmodule": "db/*=debug",
+//	                     "levels": {"debug": {"output": false, "color": true}}}
+//	GET  /_callsites    list call sites by hits, limited with ?top=N
+//	PUT  /_callsites    change state of a call site with a JSON body as:
+//	                    {"file": "db.go", "line": 42, "state": "enabled"}
+//
+// With duration, verbosity is elevated temporarily then reverted
+// to the previous one. Without duration, any temporary verbosity
+// is cancelled.
+type AdminHandler struct {
+	loggers map[string]*Logger
+	mutex   sync.RWMutex
+}
+
+// LoggerStatus describes the current state of a logger
+type LoggerStatus struct {
+	Name      string         `json:"name"`
+	Verbosity string         `json:"verbosity"`
+	Flags     []string       `json:"flags"`
+	VModule   string         `json:"vmodule"`
+	Levels    []LevelStatus  `json:"levels"`
+	Handlers  []HandlerStats `json:"handlers"`
+	Elevation *Elevation     `json:"elevation,omitempty"`
+}
+
+// LevelStatus describes the output of a level. Enabled is true
+// if message logs of the level are printed with the current verbosity.
+type LevelStatus struct {
+	Level   string `json:"level"`
+	Enabled bool   `json:"enabled"`
+	Output  bool   `json:"output"`
+	Color   bool   `json:"color"`
+}
+
+// LevelUpdate changes the output of a level
+type LevelUpdate struct {
+	Output *bool `json:"output"`
+	Color  *bool `json:"color"`
+}
+
+// Elevation describes a temporary verbosity
+type Elevation struct {
+	Previous string    `json:"previous"`
+	Until    time.Time `json:"until"`
+}
+
+// LoggerUpdate is the body of a PUT request
+type LoggerUpdate struct {
+	Verbosity string    `json:"verbosity"`
+	Flags     *[]string `json:"flags"`
+	Duration  string    `json:"duration"`
+	VModule   *string   `json:"vmodule"`
+
+	Levels map[string]LevelUpdate `json:"levels"`
+}
+
+// CallSiteUpdate is the body of a PUT request on call sites.
+// State is one of: default, enabled, disabled
+type CallSiteUpdate struct {
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	State string `json:"state"`
+}
+
+var callSiteStateNames = map[string]int{
+	"default":  CALLSITEDEFAULT,
+	"enabled":  CALLSITEENABLED,
+	"disabled": CALLSITEDISABLED,
+}
+
+// elevation is a temporary verbosity of a logger
+type elevation struct {
+	previous int
+	until    time.Time
+	timer    *time.Timer
+}
+
+// NewAdminHandler returns an admin handler with the default logger
+// registered as "default"
+func NewAdminHandler() *AdminHandler {
+	h := &AdminHandler{
+		loggers: make(map[string]*Logger),
+	}
+	h.Register("default", defaultLogger)
+
+	return h
+}
+
+// Register exposes a logger under the given name
+func (h *AdminHandler) Register(name string, l *Logger) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.loggers[name] = l
+}
+
+// ServeHTTP implements http.Handler
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(r.URL.Path, "/")
+
+	if name == "_callsites" {
+		serveCallSites(w, r)
+		return
+	}
+
+	if name == "" {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		h.mutex.RLock()
+		var names []string
+		for n := range h.loggers {
+			names = append(names, n)
+		}
+		h.mutex.RUnlock()
+		sort.Strings(names)
+
+		var statuses []LoggerStatus
+		for _, n := range names {
+			statuses = append(statuses, h.status(n))
+		}
+		writeJSON(w, http.StatusOK, statuses)
+		return
+	}
+
+	h.mutex.RLock()
+	l, ok := h.loggers[name]
+	h.mutex.RUnlock()
+
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("logger %q not found", name))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, h.status(name))
+	case http.MethodPut:
+		var update LoggerUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+			return
+		}
+
+		if err := update.apply(l); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, h.status(name))
+	default:
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *AdminHandler) status(name string) LoggerStatus {
+	h.mutex.RLock()
+	l := h.loggers[name]
+	h.mutex.RUnlock()
+
+	mutex.Lock()
+	s := LoggerStatus{
+		Name:      name,
+		Verbosity: levelName(l.verbose),
+		Flags:     flagList(l.flag),
+	}
+
+	if l.vmodule != nil {
+		s.VModule = l.vmodule.spec
+	}
+
+	for i := FATAL; i <= DEBUG; i++ {
+		s.Levels = append(s.Levels, LevelStatus{
+			Level:   prefixes[i],
+			Enabled: l.verbose >= i && !l.levels[i].disabled,
+			Output:  !l.levels[i].disabled,
+			Color:   l.levels[i].color,
+		})
+	}
+
+	if l.elevation != nil {
+		s.Elevation = &Elevation{
+			Previous: levelName(l.elevation.previous),
+			Until:    l.elevation.until,
+		}
+	}
+	mutex.Unlock()
+
+	s.Handlers = l.GetHandlerStats()
+
+	return s
+}
+
+func serveCallSites(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		sites := GetCallSites()
+		if top, err := strconv.Atoi(r.URL.Query().Get("top")); err == nil && top >= 0 && top < len(sites) {
+			sites = sites[:top]
+		}
+		writeJSON(w, http.StatusOK, sites)
+	case http.MethodPut:
+		var update CallSiteUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+			return
+		}
+
+		state, ok := callSiteStateNames[strings.ToLower(update.State)]
+		if !ok || update.File == "" || update.Line <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "file, line and state (default, enabled, disabled) are required")
+			return
+		}
+
+		n, _ := SetCallSiteState(update.File, update.Line, state)
+		writeJSON(w, http.StatusOK, map[string]int{"matched": n})
+	default:
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// apply validates then applies the update to a logger
+func (u LoggerUpdate) apply(l *Logger) error {
+	var verbose int
+	var flag int
+	var duration time.Duration
+	var err error
+
+	if u.Verbosity != "" {
+		if verbose, err = ParseLevel(u.Verbosity); err != nil {
+			return fmt.Errorf("verbosity: %s", err)
+		}
+	}
+
+	if u.Flags != nil {
+		if flag, err = ParseFlags(*u.Flags); err != nil {
+			return fmt.Errorf("flags: %s", err)
+		}
+	}
+
+	if u.Duration != "" {
+		if u.Verbosity == "" {
+			return fmt.Errorf("duration: verbosity is required")
+		}
+		if duration, err = time.ParseDuration(u.Duration); err != nil || duration <= 0 {
+			return fmt.Errorf("duration: invalid duration %q", u.Duration)
+		}
+	}
+
+	var vm *vmodule
+	if u.VModule != nil {
+		if vm, err = parseVModule(*u.VModule); err != nil {
+			return fmt.Errorf("vmodule: %s", err)
+		}
+	}
+
+	levels := make(map[int]LevelUpdate)
+	for name, lu := range u.Levels {
+		level, err := ParseLevel(name)
+		if err != nil || level == NONE {
+			return fmt.Errorf("levels: unknown level %q", name)
+		}
+		levels[level] = lu
+	}
+
+	if u.Flags != nil {
+		l.SetFlags(flag)
+	}
+
+	mutex.Lock()
+	for level, lu := range levels {
+		if lu.Output != nil {
+			l.levels[level].disabled = !*lu.Output
+		}
+		if lu.Color != nil {
+			l.setLevelColor(level, *lu.Color)
+		}
+	}
+	mutex.Unlock()
+
+	if u.VModule != nil {
+		mutex.Lock()
+		l.vmodule = vm
+		mutex.Unlock()
+	}
+
+	if u.Verbosity != "" {
+		if duration > 0 {
+			l.SetVerbosityFor(verbose, duration)
+		} else {
+			l.SetVerbosity(verbose)
+		}
+		l.notice("Logger verbosity changed", "verbosity", levelName(verbose), "duration", duration)
+	}
+
+	return nil
+}
+
+// SetVerbosityFor sets log level temporarily. After d, the level
+// in use before the first elevation is restored.
+func (l *Logger) SetVerbosityFor(v int, d time.Duration) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	previous := l.verbose
+	if l.elevation != nil {
+		previous = l.elevation.previous
+		l.stopElevation()
+	}
+
+	e := &elevation{
+		previous: previous,
+		until:    l.clock.Now().Add(d),
+	}
+	e.timer = time.AfterFunc(d, func() { l.revertVerbosity(e) })
+	l.elevation = e
+
+	l.setVerbosity(v)
+}
+
+// SetVerbosityFor sets log level of the default logger temporarily
+func SetVerbosityFor(v int, d time.Duration) {
+	defaultLogger.SetVerbosityFor(v, d)
+}
+
+// revertVerbosity restores verbosity at the end of an elevation
+func (l *Logger) revertVerbosity(e *elevation) {
+	mutex.Lock()
+	if l.elevation != e {
+		mutex.Unlock()
+		return
+	}
+	l.elevation = nil
+	l.setVerbosity(e.previous)
+	mutex.Unlock()
+
+	l.notice("Logger verbosity restored", "verbosity", levelName(e.previous))
+}
+
+// stopElevation cancels the temporary verbosity if any.
+// Global mutex must be locked by caller.
+func (l *Logger) stopElevation() {
+	if l.elevation != nil {
+		l.elevation.timer.Stop()
+		l.elevation = nil
+	}
+}
+
+func flagList(flag int) []string {
+	flags := []string{}
+	for _, name := range []string{"timestamp", "caller", "structured"} {
+		if flag&flagNames[name] != 0 {
+			flags = append(flags, name)
+		}
+	}
+
+	return flags
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,167 @@
+package golog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+)
+
+type failingHandler struct{}
+
+func (h failingHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	return errors.New("unreachable")
+}
+
+func TestAdminHandler(t *testing.T) {
+	logger := golog.NewLogger()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHandler(failingHandler{})
+	logger.Warn("This is warn log")
+
+	admin := golog.NewAdminHandler()
+	admin.Register("app", logger)
+
+	server := httptest.NewServer(admin)
+	defer server.Close()
+
+	do := func(method, path, body string) (int, golog.LoggerStatus) {
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var s golog.LoggerStatus
+		json.NewDecoder(resp.Body).Decode(&s)
+		return resp.StatusCode, s
+	}
+
+	code, s := do("GET", "/app", "")
+	if code != http.StatusOK || s.Verbosity != "INFO" || len(s.Levels) != 5 || s.Levels[4].Enabled {
+		t.Errorf("\nwant:\n200 INFO\nhave:\n%d %+v", code, s)
+	}
+
+	if len(s.Handlers) != 1 || s.Handlers[0].Healthy || s.Handlers[0].Errors != 1 || s.Handlers[0].LastError != "unreachable" {
+		t.Errorf("\nwant:\nunhealthy handler with 1 error\nhave:\n%+v", s.Handlers)
+	}
+
+	code, s = do("PUT", "/app", `{"verbosity": "debug", "flags": ["timestamp", "caller"]}`)
+	if code != http.StatusOK || logger.GetVerbosity() != golog.DEBUG || logger.GetFlags() != golog.FTIMESTAMP|golog.FCALLER {
+		t.Errorf("\nwant:\n200 DEBUG\nhave:\n%d %+v", code, s)
+	}
+
+	code, s = do("PUT", "/app", `{"verbosity": "verbose"}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("\nwant:\n400\nhave:\n%d", code)
+	}
+
+	code, _ = do("GET", "/unknown", "")
+	if code != http.StatusNotFound {
+		t.Errorf("\nwant:\n404\nhave:\n%d", code)
+	}
+
+	// Temporary elevation
+	logger.SetVerbosity(golog.WARN)
+	code, s = do("PUT", "/app", `{"verbosity": "debug", "duration": "50ms"}`)
+	if code != http.StatusOK || s.Elevation == nil || s.Elevation.Previous != "WARN" || logger.GetVerbosity() != golog.DEBUG {
+		t.Errorf("\nwant:\n200 elevated from WARN\nhave:\n%d %+v", code, s)
+	}
+
+	for i := 0; i < 100 && logger.GetVerbosity() != golog.WARN; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if logger.GetVerbosity() != golog.WARN {
+		t.Errorf("\nwant:\n%d\nhave:\n%d", golog.WARN, logger.GetVerbosity())
+	}
+
+	// Permanent verbosity cancels the elevation
+	code, s = do("PUT", "/app", `{"verbosity": "debug", "duration": "50ms"}`)
+	code, s = do("PUT", "/app", `{"verbosity": "error"}`)
+	if code != http.StatusOK || s.Elevation != nil || s.Verbosity != "ERROR" {
+		t.Errorf("\nwant:\n200 ERROR without elevation\nhave:\n%d %+v", code, s)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if logger.GetVerbosity() != golog.ERROR {
+		t.Errorf("\nwant:\n%d\nhave:\n%d", golog.ERROR, logger.GetVerbosity())
+	}
+
+	// Level output
+	code, s = do("PUT", "/app", `{"levels": {"error": {"output": false, "color": false}}}`)
+	if code != http.StatusOK || s.Levels[1].Enabled || s.Levels[1].Output || s.Levels[1].Color || !s.Levels[0].Output {
+		t.Errorf("\nwant:\n200 ERROR output disabled\nhave:\n%d %+v", code, s.Levels)
+	}
+
+	code, _ = do("PUT", "/app", `{"levels": {"verbose": {"output": true}}}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("\nwant:\n400\nhave:\n%d", code)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL+"/", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var all []golog.LoggerStatus
+	json.NewDecoder(resp.Body).Decode(&all)
+	if len(all) != 2 || all[0].Name != "app" || all[1].Name != "default" {
+		t.Errorf("\nwant:\napp, default\nhave:\n%+v", all)
+	}
+
+	resp, err = http.Get(server.URL + "/_callsites?top=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var sites []golog.CallSite
+	json.NewDecoder(resp.Body).Decode(&sites)
+	if len(sites) != 1 || sites[0].Hits == 0 {
+		t.Errorf("\nwant:\n1 call site\nhave:\n%+v", sites)
+	}
+
+	code, _ = do("PUT", "/_callsites", `{"file": "admin_test.go", "line": 26, "state": "invalid"}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("\nwant:\n400\nhave:\n%d", code)
+	}
+}
+
+func TestLogWithShared(t *testing.T) {
+	var buf syncBuffer
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.DisableColor()
+
+	// Child created during an elevation follows its end
+	logger.SetVerbosityFor(golog.DEBUG, 20*time.Millisecond)
+	child := logger.With("component", "auth")
+	for !strings.Contains(buf.String(), "Logger verbosity restored") {
+		time.Sleep(time.Millisecond)
+	}
+	restored := buf.String()
+	child.Debugw("Elevated")
+
+	logger.SetVerbosity(golog.ERROR)
+	child.Infow("Hidden")
+	child.Once().Errorw("Shown")
+
+	if v := child.GetVerbosity(); v != golog.ERROR {
+		t.Errorf("\nwant:\n%d\nhave:\n%d", golog.ERROR, v)
+	}
+
+	w := "ERROR:            Shown" + strings.Repeat(" ", 56) + "component=\"auth\"\n"
+	if have := strings.TrimPrefix(buf.String(), restored); have != w {
+		t.Errorf("\nwant:\n%q\nhave:\n%q", w, have)
+	}
+}
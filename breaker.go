@@ -0,0 +1,99 @@
+package golog
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// BREAKERCLOSED lets calls through
+	BREAKERCLOSED = iota
+	// BREAKEROPEN rejects calls until the next probe
+	BREAKEROPEN
+	// BREAKERHALFOPEN lets one probe call through
+	BREAKERHALFOPEN
+)
+
+var breakerStateNames = map[int]string{
+	BREAKERCLOSED:   "closed",
+	BREAKEROPEN:     "open",
+	BREAKERHALFOPEN: "half-open",
+}
+
+// breaker is a circuit breaker opening after a number of consecutive
+// failures and letting a probe call through after each interval
+type breaker struct {
+	threshold int
+	interval  time.Duration
+
+	state    int
+	failures int
+	retryAt  time.Time
+
+	mutex sync.Mutex
+}
+
+func newBreaker(threshold int, interval time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		interval:  interval,
+	}
+}
+
+// allow returns if a call can be made. Once the interval is elapsed,
+// the breaker becomes half-open and only one call is allowed.
+func (b *breaker) allow(now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case BREAKEROPEN:
+		if now.Before(b.retryAt) {
+			return false
+		}
+		b.state = BREAKERHALFOPEN
+		return true
+	case BREAKERHALFOPEN:
+		return false
+	}
+
+	return true
+}
+
+// rejects returns if calls are rejected at now without changing the state
+func (b *breaker) rejects(now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state == BREAKEROPEN && now.Before(b.retryAt)
+}
+
+// record updates the breaker with the result of a call.
+// It returns the previous state.
+func (b *breaker) record(err error, now time.Time) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	previous := b.state
+
+	if err == nil {
+		b.state = BREAKERCLOSED
+		b.failures = 0
+		return previous
+	}
+
+	b.failures++
+	if b.state == BREAKERHALFOPEN || b.failures >= b.threshold {
+		b.state = BREAKEROPEN
+		b.retryAt = now.Add(b.interval)
+	}
+
+	return previous
+}
+
+func (b *breaker) getState() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state
+}
@@ -0,0 +1,210 @@
+package golog
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// CALLSITEDEFAULT logs according to verbosity
+	CALLSITEDEFAULT = iota
+	// CALLSITEENABLED always logs whatever the verbosity
+	CALLSITEENABLED
+	// CALLSITEDISABLED never logs whatever the verbosity
+	CALLSITEDISABLED
+)
+
+// CallSite describes a location in source code which has logged
+type CallSite struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	Level    string `json:"level"`
+	Hits     uint64 `json:"hits"`
+	State    int    `json:"state"`
+}
+
+// callSite is a location in source code calling the logger
+type callSite struct {
+	file     string
+	line     int
+	function string
+
+	caller       string // file:line:function
+	stableCaller string // file:function
+
+	hits  uint64
+	level int32
+	state int32
+}
+
+// callSiteStates stores states set for file:line even
+// if the call site has not logged yet
+var callSiteStates = map[string]int{}
+var callSiteStatesMutex sync.Mutex
+
+type callSiteKey struct {
+	pc   uintptr
+	line int
+}
+
+// callSites caches call sites by program counter
+var callSites sync.Map
+
+// getCallSite returns the call site skip frames above
+func getCallSite(skip int) *callSite {
+	pc, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return &callSite{}
+	}
+
+	key := callSiteKey{pc: pc, line: line}
+	if s, ok := callSites.Load(key); ok {
+		return s.(*callSite)
+	}
+
+	fn := ""
+	if f := runtime.FuncForPC(pc); f != nil {
+		fn = f.Name()
+	}
+	arr := strings.Split(path.Base(fn), ".")
+
+	s := &callSite{
+		file:         file,
+		line:         line,
+		function:     fn,
+		caller:       fmt.Sprintf("%s:%d:%s", path.Base(file), line, arr[len(arr)-1]),
+		stableCaller: fmt.Sprintf("%s:%s", path.Base(file), arr[len(arr)-1]),
+	}
+
+	callSiteStatesMutex.Lock()
+	for loc, state := range callSiteStates {
+		if s.match(loc) {
+			s.state = int32(state)
+		}
+	}
+	callSites.Store(key, s)
+	callSiteStatesMutex.Unlock()
+
+	return s
+}
+
+// getCaller returns caller as file:line:function.
+// Line number is omitted if withLine is false.
+func (s *callSite) getCaller(withLine bool) string {
+	if withLine {
+		return s.caller
+	}
+
+	return s.stableCaller
+}
+
+// hit records a call at the given level and returns the call site state
+func (s *callSite) hit(level int) int {
+	atomic.AddUint64(&s.hits, 1)
+	atomic.StoreInt32(&s.level, int32(level))
+
+	return int(atomic.LoadInt32(&s.state))
+}
+
+// match returns if the call site is at location file:line. File is
+// either a file name or the end of the source path.
+func (s *callSite) match(location string) bool {
+	return location == fmt.Sprintf("%s:%d", s.file, s.line) ||
+		strings.HasSuffix(fmt.Sprintf("%s:%d", s.file, s.line), "/"+location)
+}
+
+// GetCallSites returns all call sites which have logged,
+// sorted by number of hits in descending order
+func GetCallSites() []CallSite {
+	var sites []CallSite
+
+	callSites.Range(func(k, v interface{}) bool {
+		s := v.(*callSite)
+		if hits := atomic.LoadUint64(&s.hits); hits > 0 {
+			sites = append(sites, CallSite{
+				File:     s.file,
+				Line:     s.line,
+				Function: s.function,
+				Level:    levelName(int(atomic.LoadInt32(&s.level))),
+				Hits:     hits,
+				State:    int(atomic.LoadInt32(&s.state)),
+			})
+		}
+		return true
+	})
+
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].Hits != sites[j].Hits {
+			return sites[i].Hits > sites[j].Hits
+		}
+		if sites[i].File != sites[j].File {
+			return sites[i].File < sites[j].File
+		}
+		return sites[i].Line < sites[j].Line
+	})
+
+	return sites
+}
+
+// SetCallSiteState enables, disables or resets to default the call site
+// at file:line independently of verbosity of loggers. File is either a file
+// name or the end of the source path. The state also applies if the call
+// site logs later for the first time. It returns the number of call sites
+// already known which are affected.
+func SetCallSiteState(file string, line int, state int) (int, error) {
+	if state < CALLSITEDEFAULT || state > CALLSITEDISABLED {
+		return 0, fmt.Errorf("invalid call site state %d", state)
+	}
+
+	location := fmt.Sprintf("%s:%d", file, line)
+
+	callSiteStatesMutex.Lock()
+	defer callSiteStatesMutex.Unlock()
+
+	if state == CALLSITEDEFAULT {
+		delete(callSiteStates, location)
+	} else {
+		callSiteStates[location] = state
+	}
+
+	n := 0
+	callSites.Range(func(k, v interface{}) bool {
+		s := v.(*callSite)
+		if s.match(location) {
+			atomic.StoreInt32(&s.state, int32(state))
+			n++
+		}
+		return true
+	})
+
+	return n, nil
+}
+
+// ResetCallSites forgets all call sites with their hits and states.
+// Occurrences counted by limited loggers (see Once) restart.
+func ResetCallSites() {
+	callSiteStatesMutex.Lock()
+	defer callSiteStatesMutex.Unlock()
+
+	callSiteStates = map[string]int{}
+	callSites.Range(func(k, v interface{}) bool {
+		callSites.Delete(k)
+		return true
+	})
+}
+
+// EnableCallSite always logs the call site at file:line
+func EnableCallSite(file string, line int) (int, error) {
+	return SetCallSiteState(file, line, CALLSITEENABLED)
+}
+
+// DisableCallSite never logs the call site at file:line
+func DisableCallSite(file string, line int) (int, error) {
+	return SetCallSiteState(file, line, CALLSITEDISABLED)
+}
@@ -0,0 +1,87 @@
+package golog_test
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/uthng/golog"
+)
+
+func logCallSites(logger *golog.Logger, n int) {
+	for i := 0; i < n; i++ {
+		logger.Debugln("This is debug log")
+	}
+	logger.Infoln("This is info log")
+}
+
+// logCallSitesLines returns lines of debug and info call sites of logCallSites
+func logCallSitesLines() (int, int) {
+	f := runtime.FuncForPC(reflect.ValueOf(logCallSites).Pointer())
+	_, line := f.FileLine(f.Entry())
+
+	return line + 2, line + 4
+}
+
+func TestCallSites(t *testing.T) {
+	var buf bytes.Buffer
+
+	golog.ResetCallSites()
+	defer golog.ResetCallSites()
+	debugLine, infoLine := logCallSitesLines()
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetVerbosity(golog.INFO)
+
+	// Enable a call site before it has logged
+	if _, err := golog.EnableCallSite("callsite_test.go", debugLine); err != nil {
+		t.Fatal(err)
+	}
+	defer golog.SetCallSiteState("callsite_test.go", debugLine, golog.CALLSITEDEFAULT)
+
+	logCallSites(logger, 3)
+	if n := strings.Count(buf.String(), "This is debug log"); n != 3 {
+		t.Errorf("\nwant:\n3 debug logs\nhave:\n%d", n)
+	}
+
+	var debug, info *golog.CallSite
+	for _, s := range golog.GetCallSites() {
+		s := s
+		if strings.HasSuffix(s.File, "/callsite_test.go") && s.Line == debugLine {
+			debug = &s
+		}
+		if strings.HasSuffix(s.File, "/callsite_test.go") && s.Line == infoLine {
+			info = &s
+		}
+	}
+
+	if debug == nil || debug.Hits != 3 || debug.Level != "DEBUG" || debug.State != golog.CALLSITEENABLED || !strings.HasSuffix(debug.Function, "logCallSites") {
+		t.Errorf("\nwant:\ncallsite_test.go:%d with 3 hits\nhave:\n%+v", debugLine, debug)
+	}
+
+	// Disable a known call site
+	n, _ := golog.DisableCallSite("callsite_test.go", infoLine)
+	if n != 1 {
+		t.Errorf("\nwant:\n1 call site\nhave:\n%d", n)
+	}
+
+	buf.Reset()
+	logCallSites(logger, 1)
+	if strings.Contains(buf.String(), "This is info log") {
+		t.Errorf("\nwant:\nno info log\nhave:\n%s", buf.String())
+	}
+	golog.SetCallSiteState("callsite_test.go", infoLine, golog.CALLSITEDEFAULT)
+
+	buf.Reset()
+	logCallSites(logger, 0)
+	if !strings.Contains(buf.String(), "This is info log") || info == nil || info.Hits != 1 {
+		t.Errorf("\nwant:\ninfo log\nhave:\n%s %+v", buf.String(), info)
+	}
+
+	if _, err := golog.SetCallSiteState("callsite_test.go", infoLine, 10); err == nil {
+		t.Errorf("\nwant:\nerror for invalid state\nhave:\nnil")
+	}
+}
@@ -0,0 +1,89 @@
+package golog
+
+import (
+	"time"
+)
+
+// DeterministicTime is the time used by loggers in deterministic mode
+var DeterministicTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock gives the current time used in message logs
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+type fixedClock struct {
+	t time.Time
+}
+
+// Now returns the current local time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Now always returns the same time
+func (c fixedClock) Now() time.Time {
+	return c.t
+}
+
+// FixedClock returns a clock always giving the time t
+func FixedClock(t time.Time) Clock {
+	return fixedClock{t: t}
+}
+
+// SetClock sets the clock used for timestamp. If c is nil,
+// the system clock is used.
+func (l *Logger) SetClock(c Clock) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if c == nil {
+		c = systemClock{}
+	}
+	l.clock = c
+}
+
+// EnableDeterministic makes message logs reproducible for golden file tests:
+// time is pinned to DeterministicTime, color is disabled
+// and line number is removed from caller.
+func (l *Logger) EnableDeterministic() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for i := FATAL; i <= DEBUG; i++ {
+		l.setLevelColor(i, false)
+	}
+	l.clock = FixedClock(DeterministicTime)
+	l.deterministic = true
+}
+
+// DisableDeterministic restores system clock, color and full caller
+func (l *Logger) DisableDeterministic() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for i := FATAL; i <= DEBUG; i++ {
+		l.setLevelColor(i, true)
+	}
+	l.clock = systemClock{}
+	l.deterministic = false
+}
+
+// SetClock sets the clock used for timestamp. If c is nil,
+// the system clock is used.
+func SetClock(c Clock) {
+	defaultLogger.SetClock(c)
+}
+
+// EnableDeterministic makes message logs of the default logger reproducible
+func EnableDeterministic() {
+	defaultLogger.EnableDeterministic()
+}
+
+// DisableDeterministic restores system clock, color and full caller
+// for the default logger
+func DisableDeterministic() {
+	defaultLogger.DisableDeterministic()
+}
@@ -0,0 +1,385 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is a declarative configuration of a logger
+type Config struct {
+	// Verbosity is a level name (none, fatal, error, warn, info, debug) or number
+	Verbosity string `json:"verbosity" yaml:"verbosity"`
+	// Flags is a list of: timestamp, caller, structured
+	Flags []string `json:"flags" yaml:"flags"`
+	// TimeFormat is the Go layout of timestamp
+	TimeFormat string `json:"time_format" yaml:"time_format"`
+	// Color enables or disables color. Enabled if not set
+	Color *bool `json:"color" yaml:"color"`
+	// Format is one of: text (default), structured, raw
+	Format string `json:"format" yaml:"format"`
+	// Output is the destination of all levels: stdout, stderr or a file path
+	Output string `json:"output" yaml:"output"`
+	// Outputs overrides Output for specific levels (level name => destination)
+	Outputs map[string]string `json:"outputs" yaml:"outputs"`
+	// Handlers are instantiated by type name with their options
+	Handlers []HandlerConfig `json:"handlers" yaml:"handlers"`
+	// ParallelHandlers sends message logs to handlers concurrently
+	ParallelHandlers bool `json:"parallel_handlers" yaml:"parallel_handlers"`
+}
+
+// HandlerConfig defines a handler by its registered type name and options
+type HandlerConfig struct {
+	Type    string                 `json:"type" yaml:"type"`
+	Options map[string]interface{} `json:"options" yaml:"options"`
+	// Timeout bounds calls to the handler (Go duration as "5s")
+	Timeout string `json:"timeout" yaml:"timeout"`
+}
+
+// HandlerFactory creates a handler from options of its configuration
+type HandlerFactory func(options map[string]interface{}) (Handler, error)
+
+// ConfigError lists all errors found while validating a configuration
+type ConfigError struct {
+	Errors []string
+}
+
+// config is a validated configuration ready to be applied to a logger
+type config struct {
+	verbose    int
+	flag       int
+	timeFormat string
+	color      bool
+	logFormat  bool
+	outputs    map[int]io.Writer
+	files      []*os.File
+	handlers   []*handlerEntry
+	parallel   bool
+
+	source Config
+}
+
+var handlerFactories = map[string]HandlerFactory{}
+var handlerFactoriesMutex sync.Mutex
+
+var levelNames = map[string]int{
+	"none":  NONE,
+	"fatal": FATAL,
+	"error": ERROR,
+	"warn":  WARN,
+	"info":  INFO,
+	"debug": DEBUG,
+}
+
+var flagNames = map[string]int{
+	"timestamp":  FTIMESTAMP,
+	"caller":     FCALLER,
+	"structured": FFULLSTRUCTUREDLOG,
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid logger config: " + strings.Join(e.Errors, "; ")
+}
+
+// RegisterHandler registers a handler factory under a type name
+// so that the handler can be instantiated from a configuration
+func RegisterHandler(name string, f HandlerFactory) {
+	handlerFactoriesMutex.Lock()
+	defer handlerFactoriesMutex.Unlock()
+
+	handlerFactories[strings.ToLower(name)] = f
+}
+
+// ParseLevel returns the level corresponding to a name
+// (case insensitive) or a number between NONE and DEBUG
+func ParseLevel(s string) (int, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if level, ok := levelNames[name]; ok {
+		return level, nil
+	}
+
+	if level, err := strconv.Atoi(name); err == nil && level >= NONE && level <= DEBUG {
+		return level, nil
+	}
+
+	return NONE, fmt.Errorf("unknown level %q", s)
+}
+
+// ParseFlags returns flags corresponding to a list of names:
+// timestamp, caller, structured
+func ParseFlags(names []string) (int, error) {
+	flag := 0
+	for _, name := range names {
+		f, ok := flagNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return 0, fmt.Errorf("unknown flag %q", name)
+		}
+		flag |= f
+	}
+
+	return flag, nil
+}
+
+// LoadConfig reads a logger configuration from a JSON file (.json)
+// or a YAML file (other extensions). Unknown fields are rejected.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+	} else {
+		err = yaml.UnmarshalStrict(data, cfg)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse logger config %s: %s", path, err)
+	}
+
+	return cfg, nil
+}
+
+// NewFromConfig returns a new logger built from the given configuration
+func NewFromConfig(cfg *Config) (*Logger, error) {
+	c, err := cfg.build()
+	if err != nil {
+		return nil, err
+	}
+
+	logger := NewLogger()
+	logger.apply(c)
+
+	return logger, nil
+}
+
+// build validates the configuration and instantiates outputs and handlers
+func (cfg *Config) build() (*config, error) {
+	var errs []string
+
+	c := &config{
+		verbose:    INFO,
+		timeFormat: cfg.TimeFormat,
+		color:      true,
+		logFormat:  true,
+		parallel:   cfg.ParallelHandlers,
+		outputs:    make(map[int]io.Writer),
+		source:     *cfg,
+	}
+
+	if cfg.Verbosity != "" {
+		level, err := ParseLevel(cfg.Verbosity)
+		if err != nil {
+			errs = append(errs, "verbosity: "+err.Error())
+		}
+		c.verbose = level
+	}
+
+	flag, err := ParseFlags(cfg.Flags)
+	if err != nil {
+		errs = append(errs, "flags: "+err.Error())
+	}
+	c.flag = flag
+
+	if c.timeFormat == "" {
+		c.timeFormat = time.RFC3339
+	}
+
+	if cfg.Color != nil {
+		c.color = *cfg.Color
+	}
+
+	switch strings.ToLower(cfg.Format) {
+	case "", "text":
+	case "structured":
+		c.flag |= FFULLSTRUCTUREDLOG
+	case "raw":
+		c.logFormat = false
+		c.color = false
+	default:
+		errs = append(errs, fmt.Sprintf("format: unknown format %q", cfg.Format))
+	}
+
+	for i := FATAL; i <= DEBUG; i++ {
+		dest := cfg.Output
+		for name, d := range cfg.Outputs {
+			if level, err := ParseLevel(name); err == nil && level == i {
+				dest = d
+			}
+		}
+
+		w, err := c.openOutput(i, dest)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("outputs: %s: %s", strings.ToLower(prefixes[i]), err))
+			continue
+		}
+		c.outputs[i] = w
+	}
+
+	for name := range cfg.Outputs {
+		if level, err := ParseLevel(name); err != nil || level == NONE {
+			errs = append(errs, fmt.Sprintf("outputs: unknown level %q", name))
+		}
+	}
+
+	for i, hc := range cfg.Handlers {
+		handlerFactoriesMutex.Lock()
+		f, ok := handlerFactories[strings.ToLower(hc.Type)]
+		handlerFactoriesMutex.Unlock()
+
+		if !ok {
+			errs = append(errs, fmt.Sprintf("handlers[%d]: unknown type %q (registered: %s)", i, hc.Type, registeredHandlers()))
+			continue
+		}
+
+		var timeout time.Duration
+		if hc.Timeout != "" {
+			d, err := time.ParseDuration(hc.Timeout)
+			if err != nil || d <= 0 {
+				errs = append(errs, fmt.Sprintf("handlers[%d]: timeout: invalid duration %q", i, hc.Timeout))
+				continue
+			}
+			timeout = d
+		}
+
+		h, err := f(hc.Options)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("handlers[%d]: %s: %s", i, hc.Type, err))
+			continue
+		}
+
+		e := newHandlerEntry(h)
+		e.timeout = timeout
+		c.handlers = append(c.handlers, e)
+	}
+
+	if len(errs) > 0 {
+		c.close()
+		return nil, &ConfigError{Errors: errs}
+	}
+
+	return c, nil
+}
+
+// openOutput returns the writer of a destination. By default,
+// stderr is used for FATAL and ERROR, stdout for other levels.
+func (c *config) openOutput(level int, dest string) (io.Writer, error) {
+	switch strings.ToLower(dest) {
+	case "":
+		if level == FATAL || level == ERROR {
+			return os.Stderr, nil
+		}
+		return os.Stdout, nil
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	// Levels sharing the same file use the same descriptor
+	for _, f := range c.files {
+		if f.Name() == dest {
+			return f, nil
+		}
+	}
+
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	c.files = append(c.files, f)
+
+	return f, nil
+}
+
+// close closes files opened for outputs and stops workers of handlers
+func (c *config) close() {
+	for _, f := range c.files {
+		f.Close()
+	}
+
+	for _, e := range c.handlers {
+		e.close()
+	}
+}
+
+// apply sets the configuration to the logger atomically and returns the
+// previous one. Handlers of the previous configuration are replaced,
+// handlers added with AddHandler are kept. A temporary verbosity
+// is cancelled.
+func (l *Logger) apply(c *config) *config {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	l.stopElevation()
+	l.verbose = c.verbose
+	l.flag = c.flag
+	l.timeFormat = c.timeFormat
+	l.logFormat = c.logFormat
+
+	for i := FATAL; i <= DEBUG; i++ {
+		l.levels[i].output = c.outputs[i]
+		l.setLevelColor(i, c.color)
+	}
+
+	var handlers []*handlerEntry
+	for _, e := range l.handlers {
+		if l.config == nil || !l.config.hasHandler(e) {
+			handlers = append(handlers, e)
+		}
+	}
+	l.handlers = append(handlers, c.handlers...)
+	l.parallel = c.parallel
+
+	// Files and handlers of previous config are not used anymore
+	previous := l.config
+	if previous != nil {
+		previous.close()
+	}
+	l.config = c
+
+	return previous
+}
+
+// hasHandler returns if the handler has been created by the configuration
+func (c *config) hasHandler(e *handlerEntry) bool {
+	for _, h := range c.handlers {
+		if h == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+func registeredHandlers() string {
+	handlerFactoriesMutex.Lock()
+	defer handlerFactoriesMutex.Unlock()
+
+	var names []string
+	for name := range handlerFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ", ")
+}
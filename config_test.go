@@ -0,0 +1,188 @@
+package golog_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/uthng/golog"
+)
+
+type memoryHandler struct {
+	prefix string
+	msgs   []string
+}
+
+func (h *memoryHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	h.msgs = append(h.msgs, h.prefix+fields.Log[0].Value)
+	return nil
+}
+
+func init() {
+	golog.RegisterHandler("memory", func(options map[string]interface{}) (golog.Handler, error) {
+		prefix, ok := options["prefix"].(string)
+		if !ok {
+			return nil, errors.New("prefix is required")
+		}
+		return &memoryHandler{prefix: prefix}, nil
+	})
+}
+
+func writeConfig(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "golog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(strings.ReplaceAll(content, "{dir}", dir)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			"YAML",
+			"golog.yaml",
+			`
+verbosity: debug
+flags: [timestamp, structured]
+time_format: "2006"
+color: false
+outputs:
+  error: {dir}/error.log
+  debug: {dir}/debug.log
+handlers:
+  - type: memory
+    timeout: 5s
+    options:
+      prefix: "mem: "
+`,
+		},
+		{
+			"JSON",
+			"golog.json",
+			`{
+  "verbosity": "5",
+  "flags": ["timestamp"],
+  "format": "structured",
+  "time_format": "2006",
+  "color": false,
+  "outputs": {"error": "{dir}/error.log", "debug": "{dir}/debug.log"},
+  "handlers": [{"type": "memory", "timeout": "5s", "options": {"prefix": "mem: "}}]
+}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeConfig(t, tc.file, tc.content)
+			dir := filepath.Dir(path)
+
+			cfg, err := golog.LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger, err := golog.NewFromConfig(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if logger.GetVerbosity() != golog.DEBUG {
+				t.Errorf("\nwant:\n%d\nhave:\n%d", golog.DEBUG, logger.GetVerbosity())
+			}
+
+			if logger.GetFlags() != golog.FTIMESTAMP|golog.FFULLSTRUCTUREDLOG {
+				t.Errorf("\nwant:\n%d\nhave:\n%d", golog.FTIMESTAMP|golog.FFULLSTRUCTUREDLOG, logger.GetFlags())
+			}
+
+			if stats := logger.GetHandlerStats(); len(stats) != 1 || stats[0].Timeout != "5s" {
+				t.Errorf("\nwant:\nhandler with timeout 5s\nhave:\n%+v", stats)
+			}
+
+			logger.Debugw("This is debug log")
+			logger.Errorw("This is error log")
+
+			output := map[string]string{
+				"debug.log": `^ts=\d{4} level=DEBUG msg="This is debug log"[ ]?\n$`,
+				"error.log": `^ts=\d{4} level=ERROR msg="This is error log"[ ]?\n$`,
+			}
+
+			for file, w := range output {
+				b, _ := ioutil.ReadFile(filepath.Join(dir, file))
+				matched, _ := regexp.Match(w, b)
+				if !matched {
+					t.Errorf("\nwant:\n%s\nhave:\n%s", w, b)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigUnknownField(t *testing.T) {
+	for file, content := range map[string]string{
+		"golog.yaml": "verbosity: info\nverbose: debug\n",
+		"golog.json": `{"verbosity": "info", "verbose": "debug"}`,
+	} {
+		path := writeConfig(t, file, content)
+
+		_, err := golog.LoadConfig(path)
+		if err == nil || !strings.Contains(err.Error(), "verbose") {
+			t.Errorf("\nwant:\nunknown field verbose in %s\nhave:\n%v", file, err)
+		}
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	cfg := &golog.Config{
+		Verbosity: "verbose",
+		Flags:     []string{"timestamp", "color"},
+		Format:    "xml",
+		Outputs:   map[string]string{"trace": "stdout"},
+		Handlers: []golog.HandlerConfig{
+			{Type: "unknown"},
+			{Type: "memory"},
+			{Type: "memory", Timeout: "soon", Options: map[string]interface{}{"prefix": "mem: "}},
+		},
+	}
+
+	_, err := golog.NewFromConfig(cfg)
+
+	var cfgErr *golog.ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("\nwant:\nConfigError\nhave:\n%v", err)
+	}
+
+	output := []string{
+		`verbosity: unknown level "verbose"`,
+		`flags: unknown flag "color"`,
+		`format: unknown format "xml"`,
+		`outputs: unknown level "trace"`,
+		`handlers[0]: unknown type "unknown"`,
+		`handlers[1]: memory: prefix is required`,
+		`handlers[2]: timeout: invalid duration "soon"`,
+	}
+
+	if len(cfgErr.Errors) != len(output) {
+		t.Fatalf("\nwant:\n%d errors\nhave:\n%s", len(output), err)
+	}
+
+	for idx, w := range output {
+		if !strings.HasPrefix(cfgErr.Errors[idx], w) {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", w, cfgErr.Errors[idx])
+		}
+	}
+}
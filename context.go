@@ -0,0 +1,40 @@
+package golog
+
+import (
+	"context"
+)
+
+type contextKey struct{}
+
+// With returns a child logger sharing the same settings, outputs and
+// handlers: changing them on the child or on its parent changes both.
+// The given key/value pairs are added to every message log of the child.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	child := *l
+	child.fields = append(append([]*Field{}, l.fields...), parseKeyValues(l, kv...)...)
+
+	return &child
+}
+
+// With returns a child logger of the default logger
+func With(kv ...interface{}) *Logger {
+	return defaultLogger.With(kv...)
+}
+
+// NewContext returns a copy of ctx carrying the given logger
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx.
+// If there is no logger in ctx, the default logger is returned.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+
+	return defaultLogger
+}
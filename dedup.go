@@ -0,0 +1,188 @@
+package golog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dedup collapses identical message logs received within a window.
+// Only the first one is logged, followed by a summary giving
+// the number of times it has been repeated.
+type dedup struct {
+	window time.Duration
+	keys   []string
+
+	last    string
+	start   time.Time
+	count   int
+	logger  *Logger
+	p       int
+	verbose int
+	level   int
+	caller  string
+	timer   *time.Timer
+	gen     int // identifies the current window
+}
+
+// dedupSummary is the message log giving the number of repetitions
+type dedupSummary struct {
+	logger  *Logger
+	verbose int
+	level   int
+	fields  Fields
+	caller  string
+}
+
+// SetDeduplication collapses identical message logs (same level, message
+// and values of the given field keys) logged within window into one line
+// followed by a summary "last message repeated N times". If no key is given,
+// all fields are compared. A window <= 0 disables deduplication.
+func (l *Logger) SetDeduplication(window time.Duration, keys ...string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if l.dedup != nil {
+		l.dedup.stop()
+	}
+
+	if window <= 0 {
+		l.dedup = nil
+		return
+	}
+
+	l.dedup = &dedup{
+		window: window,
+		keys:   keys,
+	}
+}
+
+// SetDeduplication collapses identical message logs of the default logger
+func SetDeduplication(window time.Duration, keys ...string) {
+	defaultLogger.SetDeduplication(window, keys...)
+}
+
+// check returns if the message log must be dispatched. If it is different
+// from the last one, the summary of the last one is returned to be
+// dispatched before. Global mutex must be locked by caller.
+func (d *dedup) check(p int, l *Logger, verbose int, level int, fields Fields, caller string) (bool, *dedupSummary) {
+	key := d.key(level, fields.Log)
+	now := l.clock.Now()
+
+	if key == d.last && now.Sub(d.start) < d.window {
+		d.count++
+		return false, nil
+	}
+
+	summary := d.flush()
+
+	d.last = key
+	d.start = now
+	d.logger = l
+	d.p = p
+	d.verbose = verbose
+	d.level = level
+	d.caller = caller
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.gen++
+	d.schedule(d.window)
+
+	return true, summary
+}
+
+// schedule calls expire for the current window after delay.
+// Global mutex must be locked by caller.
+func (d *dedup) schedule(delay time.Duration) {
+	gen := d.gen
+	d.timer = time.AfterFunc(delay, func() { d.expire(gen) })
+}
+
+// expire dispatches the summary once the window is closed according to
+// the clock of the logger. If the window has been replaced in the meantime,
+// nothing is done.
+func (d *dedup) expire(gen int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if gen != d.gen {
+		return
+	}
+
+	if remaining := d.start.Add(d.window).Sub(d.logger.clock.Now()); remaining > 0 {
+		d.schedule(remaining)
+		return
+	}
+
+	summary := d.flush()
+	d.last = ""
+	summary.dispatch()
+}
+
+// flush returns the summary of the last message log if it has been
+// repeated, nil otherwise. Global mutex must be locked by caller.
+func (d *dedup) flush() *dedupSummary {
+	if d.count <= 0 {
+		return nil
+	}
+
+	fields := Fields{}
+	fields.Prefix = parsePrefixFields(d.logger, d.level, d.caller)
+	fields.Log = parseLogFields(PRINTW, d.logger, fmt.Sprintf("last message repeated %d times", d.count))
+
+	d.count = 0
+
+	return &dedupSummary{
+		logger:  d.logger,
+		verbose: d.verbose,
+		level:   d.level,
+		fields:  fields,
+		caller:  d.caller,
+	}
+}
+
+// stop cancels the window and dispatches the pending summary.
+// Global mutex must be locked by caller.
+func (d *dedup) stop() {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.gen++
+	d.flush().dispatch()
+}
+
+// dispatch dispatches the summary if any.
+// Global mutex must be locked by caller.
+func (s *dedupSummary) dispatch() {
+	if s == nil {
+		return
+	}
+
+	dispatch(PRINTW, s.logger, s.verbose, s.level, s.fields, s.caller)
+}
+
+func (d *dedup) key(level int, fields []*Field) string {
+	var b strings.Builder
+
+	b.WriteString(prefixes[level])
+	for _, f := range fields {
+		if f.Key != "msg" && len(d.keys) > 0 && !containsString(d.keys, f.Key) {
+			continue
+		}
+		b.WriteString("\x00" + f.Key + "=" + f.Value)
+	}
+
+	return b.String()
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
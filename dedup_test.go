@@ -0,0 +1,161 @@
+package golog_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+func TestDeduplication(t *testing.T) {
+	var buf bytes.Buffer
+
+	clock := &manualClock{now: golog.DeterministicTime}
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetClock(clock)
+	logger.SetDeduplication(time.Minute, "host")
+
+	for i := 0; i < 5; i++ {
+		logger.Errorw("Upstream is down", "host", "db1", "attempt", i)
+	}
+	logger.Errorw("Upstream is down", "host", "db2", "attempt", 0)
+	logger.Errorw("Upstream is down", "host", "db2", "attempt", 1)
+
+	clock.now = clock.now.Add(time.Minute)
+	logger.Errorw("Upstream is down", "host", "db2", "attempt", 2)
+
+	output := []string{
+		`ERROR:[ ]+Upstream is down[ ]+host="db1" attempt=0$`,
+		`ERROR:[ ]+last message repeated 4 times[ ]*$`,
+		`ERROR:[ ]+Upstream is down[ ]+host="db2" attempt=0$`,
+		`ERROR:[ ]+last message repeated 1 times[ ]*$`,
+		`ERROR:[ ]+Upstream is down[ ]+host="db2" attempt=2$`,
+	}
+
+	lines := strings.Split(strings.TrimRight(utils.StringStripAnsi(buf.String()), "\n"), "\n")
+	if len(lines) != len(output) {
+		t.Fatalf("\nwant:\n%d lines\nhave:\n%s", len(output), strings.Join(lines, "\n"))
+	}
+
+	for idx, w := range output {
+		matched, _ := regexp.MatchString(w, lines[idx])
+		if !matched {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", w, lines[idx])
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.buf.String()
+}
+
+func TestDeduplicationWindowClosed(t *testing.T) {
+	var buf syncBuffer
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetDeduplication(20 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		logger.Warn("Disk almost full")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	output := `WARN:[ ]+last message repeated 2 times`
+	matched, _ := regexp.MatchString(output, utils.StringStripAnsi(buf.String()))
+	if !matched {
+		t.Errorf("\nwant:\n%s\nhave:\n%s", output, buf.String())
+	}
+}
+
+func TestDeduplicationWindowClock(t *testing.T) {
+	var buf syncBuffer
+
+	clock := &manualClock{now: golog.DeterministicTime}
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetClock(clock)
+	logger.SetDeduplication(20 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		logger.Warn("Disk almost full")
+	}
+
+	// Window is still open according to the logger clock
+	time.Sleep(60 * time.Millisecond)
+
+	output := `WARN:[ ]+last message repeated 2 times`
+	matched, _ := regexp.MatchString(output, utils.StringStripAnsi(buf.String()))
+	if matched {
+		t.Errorf("\nwant:\nno summary\nhave:\n%s", buf.String())
+	}
+
+	logger.SetClock(&manualClock{now: clock.now.Add(20 * time.Millisecond)})
+	time.Sleep(60 * time.Millisecond)
+
+	matched, _ = regexp.MatchString(output, utils.StringStripAnsi(buf.String()))
+	if !matched {
+		t.Errorf("\nwant:\n%s\nhave:\n%s", output, buf.String())
+	}
+}
+
+func TestDeduplicationHidden(t *testing.T) {
+	var buf bytes.Buffer
+
+	clock := &manualClock{now: golog.DeterministicTime}
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetClock(clock)
+	logger.SetVerbosity(golog.INFO)
+	logger.SetDeduplication(time.Minute)
+	logger.AddHandler(&countHandler{})
+
+	for i := 0; i < 3; i++ {
+		logger.Debugw("Retrying")
+		logger.Errorw("Upstream is down")
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	logger.Errorw("Upstream is back")
+
+	output := []string{
+		`ERROR:[ ]+Upstream is down[ ]*$`,
+		`ERROR:[ ]+last message repeated 2 times[ ]*$`,
+		`ERROR:[ ]+Upstream is back[ ]*$`,
+	}
+
+	lines := strings.Split(strings.TrimRight(utils.StringStripAnsi(buf.String()), "\n"), "\n")
+	if len(lines) != len(output) {
+		t.Fatalf("\nwant:\n%d lines\nhave:\n%s", len(output), strings.Join(lines, "\n"))
+	}
+
+	for idx, w := range output {
+		matched, _ := regexp.MatchString(w, lines[idx])
+		if !matched {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", w, lines[idx])
+		}
+	}
+}
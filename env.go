@@ -0,0 +1,111 @@
+package golog
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of environment variables configuring
+// the default logger
+const EnvPrefix = "GOLOG"
+
+// Suffixes of environment variables
+const (
+	envLevel      = "LEVEL"
+	envFormat     = "FORMAT"
+	envFlags      = "FLAGS"
+	envTimeFormat = "TIME_FORMAT"
+	envColor      = "COLOR"
+	envOutput     = "OUTPUT"
+)
+
+// NewLoggerFromEnv returns a new logger configured with the environment
+// variables <prefix>_LEVEL, <prefix>_FORMAT, <prefix>_FLAGS (comma separated),
+// <prefix>_TIME_FORMAT, <prefix>_COLOR and <prefix>_OUTPUT.
+// Unset variables keep the default values of NewLogger.
+func NewLoggerFromEnv(prefix string) (*Logger, error) {
+	cfg, err := configFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		return NewLogger(), nil
+	}
+
+	return NewFromConfig(cfg)
+}
+
+// configFromEnv returns the configuration defined by environment variables
+// or nil if none of them is set
+func configFromEnv(prefix string) (*Config, error) {
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	get := func(suffix string) (string, bool) {
+		return os.LookupEnv(prefix + suffix)
+	}
+
+	cfg := &Config{}
+	found := false
+
+	if v, ok := get(envLevel); ok {
+		cfg.Verbosity = v
+		found = true
+	}
+
+	if v, ok := get(envFormat); ok {
+		cfg.Format = v
+		found = true
+	}
+
+	if v, ok := get(envFlags); ok {
+		for _, f := range strings.Split(v, ",") {
+			if strings.TrimSpace(f) != "" {
+				cfg.Flags = append(cfg.Flags, f)
+			}
+		}
+		found = true
+	}
+
+	if v, ok := get(envTimeFormat); ok {
+		cfg.TimeFormat = v
+		found = true
+	}
+
+	if v, ok := get(envColor); ok {
+		color, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, &ConfigError{Errors: []string{prefix + envColor + ": invalid boolean " + strconv.Quote(v)}}
+		}
+		cfg.Color = &color
+		found = true
+	}
+
+	if v, ok := get(envOutput); ok {
+		cfg.Output = v
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return cfg, nil
+}
+
+// applyEnv configures the default logger with environment variables if set.
+// Settings made in code afterwards take precedence.
+func applyEnv() {
+	cfg, err := configFromEnv(EnvPrefix)
+	if err == nil && cfg != nil {
+		var c *config
+		if c, err = cfg.build(); err == nil {
+			defaultLogger.apply(c)
+		}
+	}
+
+	if err != nil {
+		defaultLogger.Errorw("Failed to configure logger from environment", "err", err)
+	}
+}
@@ -0,0 +1,55 @@
+package golog_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/uthng/golog"
+)
+
+func TestNewLoggerFromEnv(t *testing.T) {
+	dir := filepath.Dir(writeConfig(t, "empty", ""))
+
+	env := map[string]string{
+		"GOLOGTEST_LEVEL":       "debug",
+		"GOLOGTEST_FORMAT":      "structured",
+		"GOLOGTEST_FLAGS":       "timestamp, caller",
+		"GOLOGTEST_TIME_FORMAT": "2006",
+		"GOLOGTEST_COLOR":       "false",
+		"GOLOGTEST_OUTPUT":      filepath.Join(dir, "golog.log"),
+	}
+
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	logger, err := golog.NewLoggerFromEnv("GOLOGTEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if logger.GetVerbosity() != golog.DEBUG {
+		t.Errorf("\nwant:\n%d\nhave:\n%d", golog.DEBUG, logger.GetVerbosity())
+	}
+
+	flags := golog.FTIMESTAMP | golog.FCALLER | golog.FFULLSTRUCTUREDLOG
+	if logger.GetFlags() != flags {
+		t.Errorf("\nwant:\n%d\nhave:\n%d", flags, logger.GetFlags())
+	}
+
+	os.Setenv("GOLOGTEST_COLOR", "maybe")
+	if _, err := golog.NewLoggerFromEnv("GOLOGTEST_"); err == nil {
+		t.Errorf("\nwant:\nerror for invalid color\nhave:\nnil")
+	}
+
+	for k := range env {
+		os.Unsetenv(k)
+	}
+
+	logger, err = golog.NewLoggerFromEnv("GOLOGTEST")
+	if err != nil || logger.GetVerbosity() != golog.INFO || logger.GetFlags() != 0 {
+		t.Errorf("\nwant:\ndefault logger\nhave:\n%d %d %v", logger.GetVerbosity(), logger.GetFlags(), err)
+	}
+}
@@ -0,0 +1,129 @@
+package golog
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// FailoverOptions defines options of a failover handler
+type FailoverOptions struct {
+	// Threshold is the number of consecutive failures of the primary
+	// handler opening the circuit breaker (default 3)
+	Threshold int
+	// ProbeInterval is the interval between probes of the primary
+	// handler while the circuit breaker is open (default 30s)
+	ProbeInterval time.Duration
+	// Clock gives current time (default system time)
+	Clock Clock
+}
+
+// FailoverStats gives state and counters of a failover handler
+type FailoverStats struct {
+	State           string `json:"state"`
+	PrimaryFailures uint64 `json:"primary_failures"`
+	Failovers       uint64 `json:"failovers"`
+	Probes          uint64 `json:"probes"`
+	Restores        uint64 `json:"restores"`
+}
+
+// FailoverHandler sends message logs to a primary handler and
+// to fallback handlers when the primary one fails
+type FailoverHandler struct {
+	primary   Handler
+	fallbacks []Handler
+	clock     Clock
+	breaker   *breaker
+
+	primaryFailures uint64
+	failovers       uint64
+	probes          uint64
+	restores        uint64
+}
+
+// NewFailover returns a handler sending message logs to primary. If it fails,
+// message logs are sent to the first fallback succeeding. After Threshold
+// consecutive failures, the primary handler is not called anymore except
+// to probe it with the first message log of each ProbeInterval. It is
+// restored as soon as a probe succeeds.
+func NewFailover(primary Handler, fallbacks []Handler, opts *FailoverOptions) *FailoverHandler {
+	o := FailoverOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.Threshold <= 0 {
+		o.Threshold = 3
+	}
+
+	if o.ProbeInterval <= 0 {
+		o.ProbeInterval = 30 * time.Second
+	}
+
+	if o.Clock == nil {
+		o.Clock = systemClock{}
+	}
+
+	return &FailoverHandler{
+		primary:   primary,
+		fallbacks: append([]Handler{}, fallbacks...),
+		clock:     o.Clock,
+		breaker:   newBreaker(o.Threshold, o.ProbeInterval),
+	}
+}
+
+// PrintMsg sends message log to the primary handler or to fallbacks
+func (h *FailoverHandler) PrintMsg(p int, l *Logger, level int, fields Fields) error {
+	var errs HandlerErrors
+
+	now := h.clock.Now()
+	if h.breaker.allow(now) {
+		probe := h.breaker.getState() == BREAKERHALFOPEN
+		if probe {
+			atomic.AddUint64(&h.probes, 1)
+		}
+
+		err := h.primary.PrintMsg(p, l, level, fields)
+		previous := h.breaker.record(err, now)
+		if err == nil {
+			if previous != BREAKERCLOSED {
+				atomic.AddUint64(&h.restores, 1)
+			}
+			return nil
+		}
+
+		atomic.AddUint64(&h.primaryFailures, 1)
+		errs = append(errs, err)
+	}
+
+	atomic.AddUint64(&h.failovers, 1)
+	for _, f := range h.fallbacks {
+		err := f.PrintMsg(p, l, level, fields)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return errors.New("primary handler unavailable: circuit breaker open")
+	}
+
+	return errs
+}
+
+// Flush flushes primary and fallback handlers implementing Flusher
+func (h *FailoverHandler) Flush() error {
+	return Tee(append([]Handler{h.primary}, h.fallbacks...)...).(Flusher).Flush()
+}
+
+// Stats returns state of the circuit breaker and counters
+func (h *FailoverHandler) Stats() FailoverStats {
+	return FailoverStats{
+		State:           breakerStateNames[h.breaker.getState()],
+		PrimaryFailures: atomic.LoadUint64(&h.primaryFailures),
+		Failovers:       atomic.LoadUint64(&h.failovers),
+		Probes:          atomic.LoadUint64(&h.probes),
+		Restores:        atomic.LoadUint64(&h.restores),
+	}
+}
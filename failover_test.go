@@ -0,0 +1,77 @@
+package golog_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+)
+
+// switchHandler fails while fail is set
+type switchHandler struct {
+	memoryHandler
+	fail bool
+}
+
+func (h *switchHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	if h.fail {
+		return errors.New("unavailable")
+	}
+
+	return h.memoryHandler.PrintMsg(p, l, level, fields)
+}
+
+func TestFailoverHandler(t *testing.T) {
+	clock := &manualClock{now: golog.DeterministicTime}
+	primary := &switchHandler{fail: true}
+	fallback := &memoryHandler{}
+
+	failover := golog.NewFailover(primary, []golog.Handler{errHandler{err: errors.New("full")}, fallback},
+		&golog.FailoverOptions{Threshold: 2, ProbeInterval: time.Minute, Clock: clock})
+
+	logger := golog.NewLogger()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHandler(failover)
+
+	logger.Infow("msg 1")
+	logger.Infow("msg 2")
+	logger.Infow("msg 3")
+
+	have := failover.Stats()
+	want := golog.FailoverStats{State: "open", PrimaryFailures: 2, Failovers: 3}
+	if have != want {
+		t.Errorf("\nwant:\n%+v\nhave:\n%+v", want, have)
+	}
+
+	// Probe fails
+	clock.now = clock.now.Add(time.Minute)
+	logger.Infow("msg 4")
+
+	// Probe succeeds
+	primary.fail = false
+	clock.now = clock.now.Add(time.Minute)
+	logger.Infow("msg 5")
+	logger.Infow("msg 6")
+
+	have = failover.Stats()
+	want = golog.FailoverStats{State: "closed", PrimaryFailures: 3, Failovers: 4, Probes: 2, Restores: 1}
+	if have != want {
+		t.Errorf("\nwant:\n%+v\nhave:\n%+v", want, have)
+	}
+
+	if msgs := []string{"msg 1", "msg 2", "msg 3", "msg 4"}; !reflect.DeepEqual(fallback.msgs, msgs) {
+		t.Errorf("\nwant:\n%v\nhave:\n%v", msgs, fallback.msgs)
+	}
+
+	if msgs := []string{"msg 5", "msg 6"}; !reflect.DeepEqual(primary.msgs, msgs) {
+		t.Errorf("\nwant:\n%v\nhave:\n%v", msgs, primary.msgs)
+	}
+
+	stats := logger.GetHandlerStats()
+	if len(stats) != 1 || stats[0].Errors != 0 {
+		t.Errorf("\nwant:\nno handler error\nhave:\n%+v", stats)
+	}
+}
@@ -13,4 +13,5 @@ require (
 	github.com/stretchr/testify v1.5.1
 	github.com/uthng/goutils v0.0.0-20200327112725-3b514d880ab9
 	github.com/uthng/slack v0.5.0
+	gopkg.in/yaml.v2 v2.2.8
 )
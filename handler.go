@@ -0,0 +1,379 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HandlerStats gives health and counters of a handler
+type HandlerStats struct {
+	Name          string    `json:"name"`
+	Calls         uint64    `json:"calls"`
+	Errors        uint64    `json:"errors"`
+	Timeouts      uint64    `json:"timeouts"`
+	Drops         uint64    `json:"drops"`
+	Timeout       string    `json:"timeout,omitempty"`
+	Healthy       bool      `json:"healthy"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastErrorTime time.Time `json:"last_error_time,omitempty"`
+}
+
+// ContextHandler is implemented by handlers accepting a context to bound
+// their calls. The context is cancelled when the timeout of the handler
+// expires or when the context of the logger (see WithContext) is done.
+type ContextHandler interface {
+	PrintMsgContext(ctx context.Context, p int, l *Logger, level int, fields Fields) error
+}
+
+// handlerQueueSize is the number of message logs waiting for a handler.
+// Message logs are dropped when the queue is full.
+const handlerQueueSize = 256
+
+// handlerEntry is a handler registered in a logger with its counters.
+// Message logs are sent to the handler by a single worker, in order.
+type handlerEntry struct {
+	handler Handler
+	timeout time.Duration
+
+	calls         uint64
+	errors        uint64
+	timeouts      uint64
+	drops         uint64
+	healthy       bool
+	lastError     string
+	lastErrorTime time.Time
+	mutex         sync.Mutex
+
+	queue  chan *handlerJob
+	closed bool
+}
+
+// handlerJob is a message log waiting to be sent by the worker
+type handlerJob struct {
+	parent   context.Context // context of the logger
+	ctx      context.Context // given to ContextHandler
+	cancel   context.CancelFunc
+	timeout  time.Duration
+	deadline time.Time
+
+	p      int
+	l      *Logger
+	level  int
+	fields Fields
+
+	direct bool // called by the caller in wait
+	result chan error
+}
+
+func newHandlerEntry(h Handler) *handlerEntry {
+	return &handlerEntry{
+		handler: h,
+		healthy: true,
+	}
+}
+
+// printMsg sends message log to the handler, waits for the result
+// and updates counters
+func (e *handlerEntry) printMsg(ctx context.Context, clock Clock, p int, l *Logger, level int, fields Fields) error {
+	return e.wait(e.send(ctx, p, l, level, fields), clock)
+}
+
+// send queues message log for the worker of the handler, started on first
+// use. If the queue is full, message log is dropped. Once the entry is
+// closed, the handler is called directly by wait.
+func (e *handlerEntry) send(ctx context.Context, p int, l *Logger, level int, fields Fields) *handlerJob {
+	atomic.AddUint64(&e.calls, 1)
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	job := &handlerJob{
+		parent:  ctx,
+		ctx:     ctx,
+		cancel:  func() {},
+		timeout: time.Duration(atomic.LoadInt64((*int64)(&e.timeout))),
+		p:       p,
+		l:       l,
+		level:   level,
+		fields:  fields,
+		result:  make(chan error, 1),
+	}
+
+	if job.timeout > 0 {
+		job.deadline = time.Now().Add(job.timeout)
+		job.ctx, job.cancel = context.WithDeadline(ctx, job.deadline)
+	}
+
+	e.mutex.Lock()
+	job.direct = e.closed
+	if !job.direct {
+		if e.queue == nil {
+			e.queue = make(chan *handlerJob, handlerQueueSize)
+			go e.work(e.queue)
+		}
+
+		select {
+		case e.queue <- job:
+		default:
+			atomic.AddUint64(&e.drops, 1)
+			job.result <- fmt.Errorf("handler queue full, message log dropped")
+		}
+	}
+	e.mutex.Unlock()
+
+	return job
+}
+
+// wait returns the result of the handler. With a timeout, it stops
+// waiting once the timeout expires. A ContextHandler cancelled by the
+// context of the logger is not counted as failed.
+func (e *handlerEntry) wait(job *handlerJob, clock Clock) error {
+	defer job.cancel()
+
+	if job.direct {
+		job.result <- e.call(job.ctx, job.p, job.l, job.level, job.fields)
+	}
+
+	var expired <-chan time.Time
+	if job.timeout > 0 {
+		timer := time.NewTimer(time.Until(job.deadline))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var err error
+	select {
+	case err = <-job.result:
+	case <-expired:
+		// Let ContextHandler see the deadline rather than a cancellation
+		<-job.ctx.Done()
+		atomic.AddUint64(&e.timeouts, 1)
+		err = fmt.Errorf("handler timed out after %s", job.timeout)
+	}
+
+	if err != nil && job.parent.Err() != nil && errors.Is(err, job.parent.Err()) {
+		return nil
+	}
+	e.record(err, clock.Now())
+
+	return err
+}
+
+// timedOut returns if the timeout of the handler expired
+func (job *handlerJob) timedOut() bool {
+	return job.timeout > 0 && !time.Now().Before(job.deadline)
+}
+
+// work sends queued message logs to the handler until the entry is closed.
+// Message logs whose timeout expired while queued are skipped.
+func (e *handlerEntry) work(queue chan *handlerJob) {
+	for job := range queue {
+		if job.timedOut() {
+			job.result <- job.ctx.Err()
+			continue
+		}
+
+		job.result <- e.call(job.ctx, job.p, job.l, job.level, job.fields)
+	}
+}
+
+// close stops the worker once queued message logs are sent
+func (e *handlerEntry) close() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if !e.closed {
+		e.closed = true
+		if e.queue != nil {
+			close(e.queue)
+		}
+	}
+}
+
+func (e *handlerEntry) call(ctx context.Context, p int, l *Logger, level int, fields Fields) error {
+	if h, ok := e.handler.(ContextHandler); ok {
+		return h.PrintMsgContext(ctx, p, l, level, fields)
+	}
+
+	return e.handler.PrintMsg(p, l, level, fields)
+}
+
+// record updates health of the handler according to the result of a call
+func (e *handlerEntry) record(err error, now time.Time) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.healthy = err == nil
+	if err != nil {
+		atomic.AddUint64(&e.errors, 1)
+		e.lastError = err.Error()
+		e.lastErrorTime = now
+	}
+}
+
+func (e *handlerEntry) stats() HandlerStats {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	s := HandlerStats{
+		Name:          fmt.Sprintf("%T", e.handler),
+		Calls:         atomic.LoadUint64(&e.calls),
+		Errors:        atomic.LoadUint64(&e.errors),
+		Timeouts:      atomic.LoadUint64(&e.timeouts),
+		Drops:         atomic.LoadUint64(&e.drops),
+		Healthy:       e.healthy,
+		LastError:     e.lastError,
+		LastErrorTime: e.lastErrorTime,
+	}
+
+	if timeout := time.Duration(atomic.LoadInt64((*int64)(&e.timeout))); timeout > 0 {
+		s.Timeout = timeout.String()
+	}
+
+	return s
+}
+
+// GetHandlerStats returns health and counters of all handlers of the logger
+func (l *Logger) GetHandlerStats() []HandlerStats {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var stats []HandlerStats
+	for _, e := range l.handlers {
+		stats = append(stats, e.stats())
+	}
+
+	return stats
+}
+
+// GetHandlerStats returns health and counters of all handlers of the default logger
+func GetHandlerStats() []HandlerStats {
+	return defaultLogger.GetHandlerStats()
+}
+
+// Close flushes the logger then stops the workers calling its handlers,
+// handlers of its children included. Handlers are then called directly
+// by log calls. Close must be called once a logger is not used anymore.
+func (l *Logger) Close() {
+	l.Flush()
+
+	mutex.Lock()
+	handlers := l.handlers
+	mutex.Unlock()
+
+	for _, e := range handlers {
+		e.close()
+	}
+}
+
+// Close flushes the default logger and stops the workers of its handlers
+func Close() {
+	defaultLogger.Close()
+}
+
+// EnableParallelHandlers sends each message log to all handlers concurrently.
+// A log call then waits for the slowest handler instead of all of them in turn.
+// Each handler receives message logs in the same order as the log calls.
+func (l *Logger) EnableParallelHandlers() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	l.parallel = true
+}
+
+// DisableParallelHandlers sends each message log to handlers one after another
+func (l *Logger) DisableParallelHandlers() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	l.parallel = false
+}
+
+// EnableParallelHandlers sends message logs of the default logger to handlers concurrently
+func EnableParallelHandlers() {
+	defaultLogger.EnableParallelHandlers()
+}
+
+// DisableParallelHandlers sends message logs of the default logger to handlers one after another
+func DisableParallelHandlers() {
+	defaultLogger.DisableParallelHandlers()
+}
+
+// sendAll queues message log for all handlers at once so that they
+// are called concurrently by their workers
+func sendAll(ctx context.Context, handlers []*handlerEntry, p int, l *Logger, level int, fields Fields) []*handlerJob {
+	jobs := make([]*handlerJob, len(handlers))
+	for i, h := range handlers {
+		jobs[i] = h.send(ctx, p, l, level, fields)
+	}
+
+	return jobs
+}
+
+// waitAll returns errors of handlers in handler order once
+// all of them have returned or timed out
+func waitAll(clock Clock, handlers []*handlerEntry, jobs []*handlerJob) []error {
+	errs := make([]error, len(handlers))
+	for i, h := range handlers {
+		errs[i] = h.wait(jobs[i], clock)
+	}
+
+	return errs
+}
+
+// SetHandlerTimeout bounds calls to the handler h of the logger, and
+// of its children, to d. Once d expires, the caller stops waiting and
+// a timeout error is counted. A ContextHandler is notified with its
+// context. Message logs queued meanwhile are skipped once expired, and
+// dropped if the queue of the handler is full. A duration <= 0 removes
+// the timeout.
+func (l *Logger) SetHandlerTimeout(h Handler, d time.Duration) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, e := range l.handlers {
+		if e.handler == h {
+			atomic.StoreInt64((*int64)(&e.timeout), int64(d))
+		}
+	}
+}
+
+// SetHandlerTimeout bounds calls to the handler h of the default logger
+func SetHandlerTimeout(h Handler, d time.Duration) {
+	defaultLogger.SetHandlerTimeout(h, d)
+}
+
+// WithContext returns a child logger passing ctx to handlers
+// implementing ContextHandler
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	child := l.With()
+	child.ctx = ctx
+
+	return child
+}
+
+// outputHandler prints message logs in level outputs of the logger
+type outputHandler struct{}
+
+// NewOutputHandler returns a handler printing message logs in level outputs
+// of the logger whatever its verbosity. It allows wrapping handlers (buffering,
+// routing...) to decide what is printed. Verbosity of the logger should then be
+// set to NONE to avoid printing message logs twice.
+func NewOutputHandler() Handler {
+	return outputHandler{}
+}
+
+// PrintMsg prints message log in level output of the logger
+func (h outputHandler) PrintMsg(p int, l *Logger, level int, fields Fields) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	printMsg(p, l, DEBUG, level, fields)
+
+	return nil
+}
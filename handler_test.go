@@ -0,0 +1,282 @@
+package golog_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+)
+
+// slowHandler blocks until its context is done or unblock is closed
+type slowHandler struct {
+	unblock chan struct{}
+	ctxErr  chan error
+}
+
+func (h *slowHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	<-h.unblock
+	return nil
+}
+
+func (h *slowHandler) PrintMsgContext(ctx context.Context, p int, l *golog.Logger, level int, fields golog.Fields) error {
+	select {
+	case <-ctx.Done():
+		h.ctxErr <- ctx.Err()
+		return ctx.Err()
+	case <-h.unblock:
+		return nil
+	}
+}
+
+// blockingHandler ignores context
+type blockingHandler struct {
+	unblock chan struct{}
+}
+
+func (h *blockingHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	<-h.unblock
+	return nil
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	var buf bytes.Buffer
+
+	slow := &slowHandler{unblock: make(chan struct{}), ctxErr: make(chan error, 1)}
+	blocking := &blockingHandler{unblock: make(chan struct{})}
+	defer close(blocking.unblock)
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.AddHandler(slow)
+	logger.AddHandler(blocking)
+	logger.SetHandlerTimeout(slow, 10*time.Millisecond)
+	logger.SetHandlerTimeout(blocking, 10*time.Millisecond)
+
+	start := time.Now()
+	logger.Infow("This is info log")
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("\nwant:\nlog call bounded by timeouts\nhave:\n%s", d)
+	}
+
+	if err := <-slow.ctxErr; err != context.DeadlineExceeded {
+		t.Errorf("\nwant:\n%s\nhave:\n%v", context.DeadlineExceeded, err)
+	}
+
+	if !strings.Contains(buf.String(), "handler timed out after 10ms") {
+		t.Errorf("\nwant:\nhandler timed out after 10ms\nhave:\n%s", buf.String())
+	}
+
+	for _, s := range logger.GetHandlerStats() {
+		if s.Calls != 1 || s.Errors != 1 || s.Timeouts != 1 || s.Timeout != "10ms" {
+			t.Errorf("\nwant:\n1 call timed out\nhave:\n%+v", s)
+		}
+	}
+}
+
+func TestHandlerContext(t *testing.T) {
+	slow := &slowHandler{unblock: make(chan struct{}), ctxErr: make(chan error, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	logger := golog.NewLogger()
+	logger.SetVerbosity(golog.NONE)
+	logger.AddHandler(slow)
+
+	logger.WithContext(ctx).Infow("This is info log")
+
+	if err := <-slow.ctxErr; err != context.Canceled {
+		t.Errorf("\nwant:\n%s\nhave:\n%v", context.Canceled, err)
+	}
+
+	if s := logger.GetHandlerStats(); s[0].Errors != 0 || s[0].Timeouts != 0 || !s[0].Healthy {
+		t.Errorf("\nwant:\nno error nor timeout\nhave:\n%+v", s)
+	}
+}
+
+func TestHandlerContextCancelled(t *testing.T) {
+	var buf bytes.Buffer
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorder := &memoryHandler{}
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.AddHandler(recorder)
+
+	// Handlers not accepting a context are not affected
+	for i := 0; i < 3; i++ {
+		logger.WithContext(ctx).Infow("This is info log")
+	}
+
+	if strings.Contains(buf.String(), "Failed to print message in handler") || len(recorder.msgs) != 3 {
+		t.Errorf("\nwant:\n3 messages without error\nhave:\n%v\n%s", recorder.msgs, buf.String())
+	}
+
+	if s := logger.GetHandlerStats(); s[0].Errors != 0 || !s[0].Healthy {
+		t.Errorf("\nwant:\nhealthy handler\nhave:\n%+v", s)
+	}
+}
+
+func TestHandlerQueueFull(t *testing.T) {
+	blocking := &blockingHandler{unblock: make(chan struct{})}
+
+	logger := golog.NewLogger()
+	logger.SetVerbosity(golog.NONE)
+	logger.AddHandler(blocking)
+	logger.SetHandlerTimeout(blocking, time.Millisecond)
+
+	goroutines := runtime.NumGoroutine()
+	for i := 0; i < 300; i++ {
+		logger.Infow("This is info log")
+	}
+
+	if n := runtime.NumGoroutine() - goroutines; n > 1 {
+		t.Errorf("\nwant:\n1 worker\nhave:\n%d goroutines started", n)
+	}
+
+	s := logger.GetHandlerStats()[0]
+	if s.Calls != 300 || s.Drops == 0 || s.Timeouts+s.Drops != 300 {
+		t.Errorf("\nwant:\nmessage logs timed out or dropped\nhave:\n%+v", s)
+	}
+
+	// Worker exits once closed
+	close(blocking.unblock)
+	logger.Close()
+	for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine() - goroutines; n > 0 {
+		t.Errorf("\nwant:\nno worker\nhave:\n%d goroutines left", n)
+	}
+}
+
+func TestHandlerUnlocked(t *testing.T) {
+	blocking := &blockingHandler{unblock: make(chan struct{})}
+
+	logger := golog.NewLogger()
+	logger.SetVerbosity(golog.NONE)
+	logger.AddHandler(blocking)
+
+	done := make(chan struct{})
+	go func() {
+		logger.Infow("This is info log")
+		close(done)
+	}()
+
+	// Other loggers are not blocked while the handler is called
+	other := golog.NewLogger()
+	other.SetOutput(ioutil.Discard)
+
+	logged := make(chan struct{})
+	go func() {
+		other.Info("This is info log")
+		close(logged)
+	}()
+
+	select {
+	case <-logged:
+	case <-time.After(time.Second):
+		t.Errorf("\nwant:\nlog call not blocked\nhave:\nblocked by handler of another logger")
+	}
+
+	close(blocking.unblock)
+	<-done
+}
+
+// pairHandler succeeds only if its peer is called at the same time
+type pairHandler struct {
+	memoryHandler
+	peer chan struct{}
+	send bool
+}
+
+func (h *pairHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	if h.send {
+		select {
+		case h.peer <- struct{}{}:
+		case <-time.After(time.Second):
+			return errors.New("handlers not called concurrently")
+		}
+	} else {
+		select {
+		case <-h.peer:
+		case <-time.After(time.Second):
+			return errors.New("handlers not called concurrently")
+		}
+	}
+
+	return h.memoryHandler.PrintMsg(p, l, level, fields)
+}
+
+func TestParallelHandlers(t *testing.T) {
+	var buf bytes.Buffer
+
+	peer := make(chan struct{})
+	h1 := &pairHandler{peer: peer, send: true}
+	h2 := &pairHandler{peer: peer}
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.AddHandler(h1)
+	logger.AddHandler(h2)
+	logger.AddHandler(errHandler{err: errors.New("unavailable")})
+	logger.EnableParallelHandlers()
+
+	logger.Infow("msg 1")
+	logger.Infow("msg 2")
+	logger.Infow("msg 3")
+
+	want := []string{"msg 1", "msg 2", "msg 3"}
+	for _, h := range []*pairHandler{h1, h2} {
+		if !reflect.DeepEqual(h.msgs, want) {
+			t.Errorf("\nwant:\n%v\nhave:\n%v", want, h.msgs)
+		}
+	}
+
+	if n := strings.Count(buf.String(), "Failed to print message in handler"); n != 3 {
+		t.Errorf("\nwant:\n3 handler errors\nhave:\n%s", buf.String())
+	}
+}
+
+func TestParallelHandlersOrder(t *testing.T) {
+	var buf syncBuffer
+
+	h1 := &memoryHandler{}
+	h2 := &memoryHandler{}
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.AddHandler(h1)
+	logger.AddHandler(h2)
+	logger.EnableParallelHandlers()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				logger.Infof("msg %d-%d", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Handlers receive message logs in the order they are printed
+	printed := regexp.MustCompile(`msg [0-9]+-[0-9]+`).FindAllString(buf.String(), -1)
+
+	if len(h1.msgs) != 200 || !reflect.DeepEqual(h1.msgs, printed) || !reflect.DeepEqual(h2.msgs, printed) {
+		t.Errorf("\nwant:\n%v\nhave:\n%v\n%v", printed, h1.msgs, h2.msgs)
+	}
+}
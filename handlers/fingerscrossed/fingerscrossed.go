@@ -0,0 +1,184 @@
+// Package fingerscrossed provides a handler buffering message logs in memory
+// and forwarding them to another handler only when a severe message log arrives.
+package fingerscrossed
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	log "github.com/uthng/golog"
+)
+
+// Options defines options of the handler
+type Options struct {
+	// Size is the number of message logs buffered per logger (default 100)
+	Size int
+	// TriggerLevel is the level from which buffered message logs
+	// are flushed (default ERROR)
+	TriggerLevel int
+	// Cooldown is the duration during which message logs are passed
+	// through after a trigger
+	Cooldown time.Duration
+	// MaxBuffers is the number of loggers having a buffer (default 1000).
+	// Buffer of the least recently used logger is dropped above it.
+	MaxBuffers int
+	// Clock gives current time (default system time)
+	Clock log.Clock
+	// FlushBuffered forwards message logs still buffered when the handler
+	// is flushed, by Logger.Flush for instance. By default, they are dropped
+	// since no message log triggered them.
+	FlushBuffered bool
+}
+
+// record is a buffered message log
+type record struct {
+	p      int
+	logger *log.Logger
+	level  int
+	fields log.Fields
+}
+
+// buffer stores message logs of a logger
+type buffer struct {
+	logger  *log.Logger
+	records []record
+	until   time.Time
+}
+
+// handler buffers message logs per logger
+type handler struct {
+	next log.Handler
+	opts Options
+
+	buffers map[*log.Logger]*list.Element
+	lru     *list.List
+
+	mutex sync.Mutex
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// New returns a handler buffering the last message logs of each logger.
+// When a message log at or above the trigger level arrives, buffered message
+// logs are forwarded to next, followed by the trigger one. Message logs are then
+// passed through until cooldown ends. Child loggers created with With, such as
+// the request loggers of HTTPMiddleware, have their own buffer.
+//
+// To buffer logger outputs, wrap golog.NewOutputHandler() and
+// set verbosity of the logger to NONE.
+func New(next log.Handler, opts *Options) log.Handler {
+	h := &handler{
+		next:    next,
+		buffers: make(map[*log.Logger]*list.Element),
+		lru:     list.New(),
+	}
+
+	if opts != nil {
+		h.opts = *opts
+	}
+
+	if h.opts.Size <= 0 {
+		h.opts.Size = 100
+	}
+
+	if h.opts.TriggerLevel <= log.NONE {
+		h.opts.TriggerLevel = log.ERROR
+	}
+
+	if h.opts.MaxBuffers <= 0 {
+		h.opts.MaxBuffers = 1000
+	}
+
+	if h.opts.Clock == nil {
+		h.opts.Clock = systemClock{}
+	}
+
+	return h
+}
+
+// PrintMsg buffers message log or forwards it with buffered ones
+func (h *handler) PrintMsg(p int, l *log.Logger, level int, fields log.Fields) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := h.opts.Clock.Now()
+	b := h.buffer(l)
+	r := record{p: p, logger: l, level: level, fields: fields}
+
+	if now.Before(b.until) {
+		return h.forward(r)
+	}
+
+	if level > h.opts.TriggerLevel {
+		if len(b.records) >= h.opts.Size {
+			copy(b.records, b.records[1:])
+			b.records = b.records[:len(b.records)-1]
+		}
+		b.records = append(b.records, r)
+		return nil
+	}
+
+	records := append(b.records, r)
+	b.records = nil
+	if h.opts.Cooldown > 0 {
+		b.until = now.Add(h.opts.Cooldown)
+	}
+
+	return h.forward(records...)
+}
+
+// Flush drops buffered message logs of all loggers, or forwards
+// them with FlushBuffered option
+func (h *handler) Flush() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var err error
+	for e := h.lru.Back(); e != nil; e = e.Prev() {
+		b := e.Value.(*buffer)
+		if h.opts.FlushBuffered {
+			if ferr := h.forward(b.records...); ferr != nil {
+				err = ferr
+			}
+		}
+		b.records = nil
+	}
+
+	return err
+}
+
+// buffer returns the buffer of a logger and marks it as the most recently used
+func (h *handler) buffer(l *log.Logger) *buffer {
+	if e, ok := h.buffers[l]; ok {
+		h.lru.MoveToFront(e)
+		return e.Value.(*buffer)
+	}
+
+	b := &buffer{logger: l}
+	h.buffers[l] = h.lru.PushFront(b)
+
+	if h.lru.Len() > h.opts.MaxBuffers {
+		e := h.lru.Back()
+		h.lru.Remove(e)
+		delete(h.buffers, e.Value.(*buffer).logger)
+	}
+
+	return b
+}
+
+// forward sends records to the next handler. The last error is returned.
+func (h *handler) forward(records ...record) error {
+	var err error
+	for _, r := range records {
+		if e := h.next.PrintMsg(r.p, r.logger, r.level, r.fields); e != nil {
+			err = e
+		}
+	}
+
+	return err
+}
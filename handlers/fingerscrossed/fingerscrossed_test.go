@@ -0,0 +1,109 @@
+package fingerscrossed
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uthng/golog"
+	"github.com/uthng/golog/logtest"
+)
+
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func msgs(entries []logtest.Entry) []string {
+	var res []string
+	for _, e := range entries {
+		res = append(res, e.Msg)
+	}
+
+	return res
+}
+
+func TestHandlerFingersCrossed(t *testing.T) {
+	clock := &manualClock{now: golog.DeterministicTime}
+	recorder := &logtest.Recorder{}
+
+	logger := golog.NewLogger()
+	logger.SetVerbosity(golog.DEBUG)
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHandler(New(recorder, &Options{Size: 2, Cooldown: time.Minute, Clock: clock}))
+
+	logger.Debugw("debug 1")
+	logger.Infow("info 1")
+	logger.Warnw("warn 1")
+	assert.Empty(t, recorder.Entries())
+
+	logger.Errorw("error 1")
+	assert.Equal(t, []string{"info 1", "warn 1", "error 1"}, msgs(recorder.Entries()))
+
+	// Cooldown
+	recorder.Reset()
+	logger.Debugw("debug 2")
+	assert.Equal(t, []string{"debug 2"}, msgs(recorder.Entries()))
+
+	// Buffered again after cooldown
+	recorder.Reset()
+	clock.now = clock.now.Add(time.Minute)
+	logger.Debugw("debug 3")
+	assert.Empty(t, recorder.Entries())
+
+	// Each child logger has its own buffer
+	child := logger.With("request_id", "1")
+	child.Infow("child info")
+	child.Errorw("child error")
+	assert.Equal(t, []string{"child info", "child error"}, msgs(recorder.Entries()))
+
+	// Buffers not triggered are dropped on flush
+	recorder.Reset()
+	logger.Flush()
+	assert.Empty(t, recorder.Entries())
+
+	logger.Errorw("error 2")
+	assert.Equal(t, []string{"error 2"}, msgs(recorder.Entries()))
+}
+
+func TestHandlerFingersCrossedFlushBuffered(t *testing.T) {
+	recorder := &logtest.Recorder{}
+
+	logger := golog.NewLogger()
+	logger.SetVerbosity(golog.DEBUG)
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHandler(New(recorder, &Options{FlushBuffered: true}))
+
+	logger.Debugw("debug")
+	assert.Empty(t, recorder.Entries())
+
+	logger.Flush()
+	assert.Equal(t, []string{"debug"}, msgs(recorder.Entries()))
+}
+
+func TestHandlerFingersCrossedOutput(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := golog.NewLogger()
+	logger.SetVerbosity(golog.NONE)
+	logger.SetOutput(&buf)
+	logger.DisableColor()
+	logger.AddHandler(New(golog.NewOutputHandler(), &Options{MaxBuffers: 1}))
+
+	logger.With("request_id", "1").Debugw("dropped")
+	logger.Debugw("debug")
+	assert.Empty(t, buf.String())
+
+	logger.Errorw("error")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "DEBUG:")
+	assert.Contains(t, lines[1], "ERROR:")
+}
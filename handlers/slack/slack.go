@@ -1,12 +1,17 @@
 package slack
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	//"time"
 
-	//"github.com/spf13/cast"
+	"github.com/spf13/cast"
 	"github.com/uthng/slack"
 
 	log "github.com/uthng/golog"
@@ -26,6 +31,16 @@ type handler struct {
 	mutex sync.Mutex
 }
 
+// webhookMessage is the payload of an incoming webhook
+type webhookMessage struct {
+	Username    string             `json:"username,omitempty"`
+	IconEmoji   string             `json:"icon_emoji,omitempty"`
+	IconURL     string             `json:"icon_url,omitempty"`
+	Channel     string             `json:"channel,omitempty"`
+	Text        string             `json:"text,omitempty"`
+	Attachments []slack.Attachment `json:"attachments,omitempty"`
+}
+
 // Color Map following levels
 var colors = map[int]string{
 	log.FATAL: "#cc0000",
@@ -35,6 +50,10 @@ var colors = map[int]string{
 	log.DEBUG: "#7e7e7c",
 }
 
+func init() {
+	log.RegisterHandler("slack", newFromOptions)
+}
+
 // New creates a new slack handler
 func New(webhookURL, username, iconEmoji, iconURL, channel, title string, verbose int) log.Handler {
 	s := &handler{
@@ -50,9 +69,41 @@ func New(webhookURL, username, iconEmoji, iconURL, channel, title string, verbos
 	return s
 }
 
+// newFromOptions creates a new slack handler from configuration options
+func newFromOptions(options map[string]interface{}) (log.Handler, error) {
+	webhookURL := cast.ToString(options["webhook_url"])
+	if webhookURL == "" {
+		return nil, errors.New("webhook_url is required")
+	}
+
+	verbose := log.INFO
+	if v, ok := options["verbose"]; ok {
+		level, err := log.ParseLevel(cast.ToString(v))
+		if err != nil {
+			return nil, fmt.Errorf("verbose: %s", err)
+		}
+		verbose = level
+	}
+
+	return New(
+		webhookURL,
+		cast.ToString(options["username"]),
+		cast.ToString(options["icon_emoji"]),
+		cast.ToString(options["icon_url"]),
+		cast.ToString(options["channel"]),
+		cast.ToString(options["title"]),
+		verbose), nil
+}
+
 // PrintMsg formats messages to post to slack channel
 // according to logger informations
 func (h *handler) PrintMsg(p int, l *log.Logger, level int, fields log.Fields) error {
+	return h.PrintMsgContext(context.Background(), p, l, level, fields)
+}
+
+// PrintMsgContext posts messages to slack channel. The request
+// is cancelled when ctx is done.
+func (h *handler) PrintMsgContext(ctx context.Context, p int, l *log.Logger, level int, fields log.Fields) error {
 
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
@@ -61,22 +112,22 @@ func (h *handler) PrintMsg(p int, l *log.Logger, level int, fields log.Fields) e
 		//color := colors[level]
 		switch p {
 		case log.PRINT, log.PRINTF, log.PRINTLN:
-			return h.printWebhook(level, fields)
+			return h.printWebhook(ctx, level, fields)
 		case log.PRINTW:
 			flag := l.GetFlags()
 			if flag&log.FFULLSTRUCTUREDLOG != 0 {
-				return h.printwWebhook(level, fields, true)
+				return h.printwWebhook(ctx, level, fields, true)
 			}
 
-			return h.printwWebhook(level, fields, false)
+			return h.printwWebhook(ctx, level, fields, false)
 		}
 	}
 
 	return nil
 }
 
-func (h *handler) printWebhook(level int, fields log.Fields) error {
-	var webhookMsg *slack.WebhookMessage
+func (h *handler) printWebhook(ctx context.Context, level int, fields log.Fields) error {
+	var webhookMsg *webhookMessage
 	var attachment *slack.Attachment
 	var prefix string
 
@@ -97,11 +148,11 @@ func (h *handler) printWebhook(level int, fields log.Fields) error {
 
 	attachment.Text = prefix + message
 
-	return slack.PostWebhook(h.webhookURL, webhookMsg)
+	return h.postWebhook(ctx, webhookMsg)
 }
 
-func (h *handler) printwWebhook(level int, fields log.Fields, full bool) error {
-	var webhookMsg *slack.WebhookMessage
+func (h *handler) printwWebhook(ctx context.Context, level int, fields log.Fields, full bool) error {
+	var webhookMsg *webhookMessage
 	var attachment *slack.Attachment
 
 	webhookMsg = initWebhookMessage(h, level)
@@ -127,11 +178,38 @@ func (h *handler) printwWebhook(level int, fields log.Fields, full bool) error {
 		attachment.Fields = append(attachment.Fields, field)
 	}
 
-	return slack.PostWebhook(h.webhookURL, webhookMsg)
+	return h.postWebhook(ctx, webhookMsg)
+}
+
+// postWebhook sends the message to the webhook URL. An error status
+// is returned as HTTPError so that a retry handler honors Retry-After.
+func (h *handler) postWebhook(ctx context.Context, msg *webhookMessage) error {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal failed: %s", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.webhookURL, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return log.NewHTTPError(resp)
+	}
+
+	return nil
 }
 
-func initWebhookMessage(h *handler, level int) *slack.WebhookMessage {
-	webhookMsg := &slack.WebhookMessage{
+func initWebhookMessage(h *handler, level int) *webhookMessage {
+	webhookMsg := &webhookMessage{
 		Username:  h.username,
 		IconEmoji: h.iconEmoji,
 		IconURL:   h.iconURL,
@@ -18,9 +18,9 @@ import (
 )
 
 func TestHandlerSlackSimpleLog(t *testing.T) {
-	output := anotherSlack.WebhookMessage{}
-	outputDebug := anotherSlack.WebhookMessage{}
-	outputInfo := anotherSlack.WebhookMessage{
+	output := webhookMessage{}
+	outputDebug := webhookMessage{}
+	outputInfo := webhookMessage{
 		Channel:  "C4JLPQB7X",
 		Username: "Golog",
 		Attachments: []anotherSlack.Attachment{
@@ -31,7 +31,7 @@ func TestHandlerSlackSimpleLog(t *testing.T) {
 			},
 		},
 	}
-	outputWarn := anotherSlack.WebhookMessage{
+	outputWarn := webhookMessage{
 		Channel:  "C4JLPQB7X",
 		Username: "Golog",
 		Attachments: []anotherSlack.Attachment{
@@ -42,7 +42,7 @@ func TestHandlerSlackSimpleLog(t *testing.T) {
 			},
 		},
 	}
-	outputErrorSimple := anotherSlack.WebhookMessage{
+	outputErrorSimple := webhookMessage{
 		Channel:  "C4JLPQB7X",
 		Username: "Golog",
 		Attachments: []anotherSlack.Attachment{
@@ -53,7 +53,7 @@ func TestHandlerSlackSimpleLog(t *testing.T) {
 			},
 		},
 	}
-	outputErrorSemiStructured := anotherSlack.WebhookMessage{
+	outputErrorSemiStructured := webhookMessage{
 		Channel:  "C4JLPQB7X",
 		Username: "Golog",
 		Attachments: []anotherSlack.Attachment{
@@ -63,10 +63,10 @@ func TestHandlerSlackSimpleLog(t *testing.T) {
 				Text:       "This is error log",
 				Fields: []anotherSlack.AttachmentField{
 					{
-						Value: "*Field1*: value1",
+						Value: "*Field1*: \"value1\"",
 					},
 					{
-						Value: "*Field2*: value2",
+						Value: "*Field2*: \"value2\"",
 					},
 					{
 						Value: "*Level*: ERROR",
@@ -111,9 +111,9 @@ func TestHandlerSlackSimpleLog(t *testing.T) {
 }
 
 func TestHandlerSlackStructuredLog(t *testing.T) {
-	output := anotherSlack.WebhookMessage{}
-	outputDebug := anotherSlack.WebhookMessage{}
-	outputInfo := anotherSlack.WebhookMessage{
+	output := webhookMessage{}
+	outputDebug := webhookMessage{}
+	outputInfo := webhookMessage{
 		Channel:  "C4JLPQB7X",
 		Username: "Golog",
 		Attachments: []anotherSlack.Attachment{
@@ -126,10 +126,10 @@ func TestHandlerSlackStructuredLog(t *testing.T) {
 						Value: "*Msg*: This is info log",
 					},
 					{
-						Value: "*Field1*: value1",
+						Value: "*Field1*: \"value1\"",
 					},
 					{
-						Value: "*Field2*: value2",
+						Value: "*Field2*: \"value2\"",
 					},
 					{
 						Value: "*Caller*: slack_test.go:225:TestHandlerSlackStructuredLog",
@@ -141,7 +141,7 @@ func TestHandlerSlackStructuredLog(t *testing.T) {
 			},
 		},
 	}
-	outputWarn := anotherSlack.WebhookMessage{
+	outputWarn := webhookMessage{
 		Channel:  "C4JLPQB7X",
 		Username: "Golog",
 		Attachments: []anotherSlack.Attachment{
@@ -154,10 +154,10 @@ func TestHandlerSlackStructuredLog(t *testing.T) {
 						Value: "*Msg*: This is warn log",
 					},
 					{
-						Value: "*Field1*: value1",
+						Value: "*Field1*: \"value1\"",
 					},
 					{
-						Value: "*Field2*: value2",
+						Value: "*Field2*: \"value2\"",
 					},
 					{
 						Value: "*Caller*: slack_test.go:231:TestHandlerSlackStructuredLog",
@@ -169,7 +169,7 @@ func TestHandlerSlackStructuredLog(t *testing.T) {
 			},
 		},
 	}
-	outputError := anotherSlack.WebhookMessage{
+	outputError := webhookMessage{
 		Channel:  "C4JLPQB7X",
 		Username: "Golog",
 		Attachments: []anotherSlack.Attachment{
@@ -182,10 +182,10 @@ func TestHandlerSlackStructuredLog(t *testing.T) {
 						Value: "*Msg*: This is error log",
 					},
 					{
-						Value: "*Field1*: value1",
+						Value: "*Field1*: \"value1\"",
 					},
 					{
-						Value: "*Field2*: value2",
+						Value: "*Field2*: \"value2\"",
 					},
 					{
 						Value: "*Caller*: slack_test.go:237:TestHandlerSlackStructuredLog",
@@ -240,7 +240,7 @@ func TestHandlerSlackStructuredLog(t *testing.T) {
 	assert.Equal(t, outputError, output)
 }
 
-func insertTsInOutput(output anotherSlack.WebhookMessage, ts string) anotherSlack.WebhookMessage {
+func insertTsInOutput(output webhookMessage, ts string) webhookMessage {
 
 	fields := output.Attachments[0].Fields
 
@@ -254,3 +254,48 @@ func insertTsInOutput(output anotherSlack.WebhookMessage, ts string) anotherSlac
 
 	return output
 }
+
+func TestHandlerSlackContext(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-unblock:
+		}
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	logger := golog.NewLogger()
+	logger.SetVerbosity(golog.NONE)
+
+	h := New(server.URL, "Golog", "", "", "C4JLPQB7X", "", golog.INFO)
+	logger.AddHandler(h)
+	logger.SetHandlerTimeout(h, 10*time.Millisecond)
+
+	start := time.Now()
+	logger.Info("This is info log")
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	stats := logger.GetHandlerStats()
+	assert.Equal(t, uint64(1), stats[0].Timeouts)
+}
+
+func TestHandlerSlackHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	logger := golog.NewLogger()
+	h := New(server.URL, "Golog", "", "", "C4JLPQB7X", "", golog.INFO)
+
+	err := h.PrintMsg(golog.PRINTW, logger, golog.INFO, golog.Fields{Log: []*golog.Field{{Key: "msg", Value: "This is info log"}}})
+
+	httpErr, ok := err.(*golog.HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusTooManyRequests, httpErr.StatusCode)
+		assert.Equal(t, 30*time.Second, httpErr.RetryAfter)
+	}
+}
@@ -0,0 +1,174 @@
+package golog
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultRequestIDHeader is the header used by HTTPMiddleware
+// to propagate request ID if no header is configured
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// HTTPOptions defines options of HTTPMiddleware
+type HTTPOptions struct {
+	// RequestIDHeader is the header read to get request ID.
+	// If absent in request, a new ID is generated and set in response.
+	RequestIDHeader string
+	// CombinedLogFormat logs requests with Apache combined log format
+	// instead of structured fields
+	CombinedLogFormat bool
+	// Level returns log level according to response status.
+	// By default: 5xx = ERROR, 4xx = WARN, others = INFO
+	Level func(status int) int
+}
+
+// responseWriter captures status and bytes written by a handler
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// HTTPMiddleware returns a middleware logging each request served
+// by the wrapped handler. A child logger with request ID is injected
+// in request context and can be retrieved with FromContext.
+func HTTPMiddleware(l *Logger, opts *HTTPOptions) func(http.Handler) http.Handler {
+	o := HTTPOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.RequestIDHeader == "" {
+		o.RequestIDHeader = DefaultRequestIDHeader
+	}
+
+	if o.Level == nil {
+		o.Level = levelFromStatus
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := l.clock.Now()
+
+			id := r.Header.Get(o.RequestIDHeader)
+			if id == "" {
+				id = newRequestID()
+			}
+			w.Header().Set(o.RequestIDHeader, id)
+
+			logger := l.With("request_id", id)
+			rw := &responseWriter{ResponseWriter: w}
+
+			next.ServeHTTP(rw, r.WithContext(NewContext(r.Context(), logger)))
+
+			if rw.status == 0 {
+				rw.status = http.StatusOK
+			}
+
+			level := o.Level(rw.status)
+			if o.CombinedLogFormat {
+				logDepth(0, PRINTLN, logger, level, "", formatCombinedLog(r, rw, start))
+				return
+			}
+
+			logDepth(0, PRINTW, logger, level, "http request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rw.status,
+				"bytes", rw.bytes,
+				"latency", l.clock.Now().Sub(start),
+				"remote_addr", r.RemoteAddr,
+				"user_agent", r.UserAgent())
+		})
+	}
+}
+
+// WriteHeader records status code before writing it
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer does
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the underlying writer does
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not implement http.Hijacker")
+	}
+
+	return h.Hijack()
+}
+
+func levelFromStatus(status int) int {
+	switch {
+	case status >= 500:
+		return ERROR
+	case status >= 400:
+		return WARN
+	default:
+		return INFO
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// formatCombinedLog formats a request with Apache combined log format:
+// host ident authuser [date] "request" status bytes "referer" "user-agent"
+func formatCombinedLog(r *http.Request, w *responseWriter, t time.Time) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	user := "-"
+	if r.URL.User != nil && r.URL.User.Username() != "" {
+		user = r.URL.User.Username()
+	}
+
+	referer := r.Referer()
+	if referer == "" {
+		referer = "-"
+	}
+
+	ua := r.UserAgent()
+	if ua == "" {
+		ua = "-"
+	}
+
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %d \"%s\" \"%s\"",
+		host, user, t.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, r.RequestURI, r.Proto, w.status, w.bytes, referer, ua)
+}
@@ -0,0 +1,110 @@
+package golog_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     *golog.HTTPOptions
+		status   int
+		reqID    string
+		output   string
+		respID   string
+		ctxLevel string
+	}{
+		{
+			"Structured",
+			nil,
+			http.StatusOK,
+			"abc",
+			`INFO:[ ]+http request[ ]+request_id="abc" method="GET" path="/users" status=200 bytes=5 latency=(.*) remote_addr="192.0.2.1:1234" user_agent="golog-test"`,
+			"abc",
+			"",
+		},
+		{
+			"StructuredWarn",
+			nil,
+			http.StatusNotFound,
+			"def",
+			`WARN:[ ]+http request[ ]+request_id="def" method="GET" path="/users" status=404`,
+			"def",
+			"",
+		},
+		{
+			"StructuredError",
+			&golog.HTTPOptions{RequestIDHeader: "X-Trace"},
+			http.StatusBadGateway,
+			"",
+			`ERROR:[ ]+http request[ ]+request_id="[0-9a-f]{32}" method="GET"`,
+			"",
+			"",
+		},
+		{
+			"Combined",
+			&golog.HTTPOptions{CombinedLogFormat: true},
+			http.StatusCreated,
+			"ghi",
+			`INFO:[ ]+192.0.2.1 - - \[.*\] "GET /users HTTP/1.1" 201 5 "-" "golog-test"`,
+			"ghi",
+			"",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			multi := io.MultiWriter(&buf, os.Stdout)
+			logger := golog.NewLogger()
+			logger.SetOutput(multi)
+
+			header := golog.DefaultRequestIDHeader
+			if tc.opts != nil && tc.opts.RequestIDHeader != "" {
+				header = tc.opts.RequestIDHeader
+			}
+
+			var ctxLogger *golog.Logger
+			h := golog.HTTPMiddleware(logger, tc.opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxLogger = golog.FromContext(r.Context())
+				w.WriteHeader(tc.status)
+				w.Write([]byte("hello"))
+			}))
+
+			req := httptest.NewRequest("GET", "/users", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("User-Agent", "golog-test")
+			if tc.reqID != "" {
+				req.Header.Set(header, tc.reqID)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			msg := utils.StringStripAnsi(buf.String())
+			matched, _ := regexp.MatchString(tc.output, msg)
+			if !matched {
+				t.Errorf("\nwant:\n%s\nhave:\n%s", tc.output, msg)
+			}
+
+			respID := rec.Header().Get(header)
+			if respID == "" || (tc.respID != "" && respID != tc.respID) {
+				t.Errorf("\nwant request id:\n%s\nhave:\n%s", tc.respID, respID)
+			}
+
+			if ctxLogger == nil || ctxLogger == logger {
+				t.Errorf("child logger not found in request context")
+			}
+		})
+	}
+}
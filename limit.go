@@ -0,0 +1,128 @@
+package golog
+
+import (
+	"time"
+)
+
+const (
+	// LIMITONCE logs only the first occurrence
+	LIMITONCE = iota
+	// LIMITEVERYN logs one occurrence out of N
+	LIMITEVERYN
+	// LIMITFIRSTN logs the N first occurrences
+	LIMITFIRSTN
+	// LIMITEVERY logs at most one occurrence per interval
+	LIMITEVERY
+)
+
+// limiter restricts the number of message logs per call site
+type limiter struct {
+	kind     int
+	n        int
+	interval time.Duration
+}
+
+type limitKey struct {
+	site    *callSite
+	limiter limiter
+}
+
+// limitState counts occurrences of a call site for a limiter
+type limitState struct {
+	count   int
+	skipped int
+	last    time.Time
+}
+
+// Once returns a logger which logs only the first occurrence
+// of each call site: l.Once().Warnw("..."). Occurrences are counted
+// per logger, its children included.
+func (l *Logger) Once() *Logger {
+	return l.withLimiter(limiter{kind: LIMITONCE})
+}
+
+// EveryN returns a logger which logs the first occurrence of each
+// call site then one occurrence out of n
+func (l *Logger) EveryN(n int) *Logger {
+	return l.withLimiter(limiter{kind: LIMITEVERYN, n: n})
+}
+
+// FirstN returns a logger which logs only the n first occurrences
+// of each call site
+func (l *Logger) FirstN(n int) *Logger {
+	return l.withLimiter(limiter{kind: LIMITFIRSTN, n: n})
+}
+
+// Every returns a logger which logs at most one occurrence
+// of each call site per interval d
+func (l *Logger) Every(d time.Duration) *Logger {
+	return l.withLimiter(limiter{kind: LIMITEVERY, interval: d})
+}
+
+// Once returns a default logger logging only the first occurrence
+func Once() *Logger {
+	return defaultLogger.Once()
+}
+
+// EveryN returns a default logger logging one occurrence out of n
+func EveryN(n int) *Logger {
+	return defaultLogger.EveryN(n)
+}
+
+// FirstN returns a default logger logging the n first occurrences
+func FirstN(n int) *Logger {
+	return defaultLogger.FirstN(n)
+}
+
+// Every returns a default logger logging one occurrence per interval d
+func Every(d time.Duration) *Logger {
+	return defaultLogger.Every(d)
+}
+
+func (l *Logger) withLimiter(lim limiter) *Logger {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	child := *l
+	child.limiter = &lim
+
+	return &child
+}
+
+// allow returns if the occurrence of the call site must be logged
+// and the number of occurrences skipped since the last one logged.
+// Global mutex must be locked by caller.
+func (lim *limiter) allow(states map[limitKey]*limitState, site *callSite, now time.Time) (bool, int) {
+	key := limitKey{site: site, limiter: *lim}
+
+	s, ok := states[key]
+	if !ok {
+		s = &limitState{}
+		states[key] = s
+	}
+
+	s.count++
+
+	allowed := false
+	switch lim.kind {
+	case LIMITONCE:
+		allowed = s.count == 1
+	case LIMITEVERYN:
+		allowed = lim.n <= 1 || (s.count-1)%lim.n == 0
+	case LIMITFIRSTN:
+		allowed = s.count <= lim.n
+	case LIMITEVERY:
+		allowed = s.count == 1 || now.Sub(s.last) >= lim.interval
+	}
+
+	if !allowed {
+		s.skipped++
+		return false, 0
+	}
+
+	skipped := s.skipped
+	s.skipped = 0
+	s.last = now
+
+	return true, skipped
+}
@@ -0,0 +1,109 @@
+package golog_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+func TestLimit(t *testing.T) {
+	var buf bytes.Buffer
+
+	clock := &manualClock{now: golog.DeterministicTime}
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetClock(clock)
+
+	for i := 0; i < 7; i++ {
+		logger.Once().Warnw("Once", "i", i)
+		logger.EveryN(3).Infow("EveryN", "i", i)
+		logger.FirstN(2).Errorw("FirstN", "i", i)
+		logger.Every(time.Minute).Infow("Every", "i", i)
+		clock.now = clock.now.Add(25 * time.Second)
+	}
+
+	output := []string{
+		`WARN:[ ]+Once[ ]+i=0$`,
+		`INFO:[ ]+EveryN[ ]+i=0$`,
+		`ERROR:[ ]+FirstN[ ]+i=0$`,
+		`INFO:[ ]+Every[ ]+i=0$`,
+		`ERROR:[ ]+FirstN[ ]+i=1$`,
+		`INFO:[ ]+EveryN[ ]+i=3[ ]+skipped=2$`,
+		`INFO:[ ]+Every[ ]+i=3[ ]+skipped=2$`,
+		`INFO:[ ]+EveryN[ ]+i=6[ ]+skipped=2$`,
+		`INFO:[ ]+Every[ ]+i=6[ ]+skipped=2$`,
+	}
+
+	lines := strings.Split(strings.TrimRight(utils.StringStripAnsi(buf.String()), "\n"), "\n")
+	if len(lines) != len(output) {
+		t.Fatalf("\nwant:\n%d lines\nhave:\n%s", len(output), buf.String())
+	}
+
+	for idx, w := range output {
+		matched, _ := regexp.MatchString(w, lines[idx])
+		if !matched {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", w, lines[idx])
+		}
+	}
+}
+
+func TestLimitConcurrent(t *testing.T) {
+	recorder := &countHandler{}
+
+	logger := golog.NewLogger()
+	logger.SetVerbosity(golog.NONE)
+	logger.AddHandler(recorder)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				logger.FirstN(5).Warnw("Concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&recorder.n); n != 5 {
+		t.Errorf("\nwant:\n5 messages\nhave:\n%d", n)
+	}
+}
+
+func TestLimitScope(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetVerbosity(golog.WARN)
+
+	other := golog.NewLogger()
+	other.SetOutput(&buf)
+
+	for _, l := range []*golog.Logger{logger, logger, other} {
+		// Occurrences of disabled levels are not counted
+		l.Once().Infow("Once")
+		logger.SetVerbosity(golog.INFO)
+	}
+
+	if n := strings.Count(buf.String(), "Once"); n != 2 {
+		t.Errorf("\nwant:\n2 messages, 1 per logger\nhave:\n%s", buf.String())
+	}
+}
+
+type countHandler struct {
+	n int32
+}
+
+func (h *countHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	atomic.AddInt32(&h.n, 1)
+	return nil
+}
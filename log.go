@@ -1,14 +1,14 @@
 package golog
 
 import (
+	"context"
 	"io"
 	//"io/ioutil"
 	"fmt"
 	//"log"
 	"os"
-	"path"
 	"reflect"
-	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -68,11 +68,13 @@ var colors = map[int][]color.Attribute{
 	DEBUG: []color.Attribute{color.FgWhite},
 }
 
-var mutex Mutex
+var mutex sync.Mutex
 var wg sync.WaitGroup
 
 type level struct {
 	output      io.Writer
+	disabled    bool // output switched off
+	sampler     *Sampler
 	color       bool
 	colorPrefix *color.Color
 	colorText   *color.Color
@@ -80,13 +82,37 @@ type level struct {
 
 // Logger is a wrapper of go log integrating log level
 type Logger struct {
+	*core
+	fields   []*Field // static fields added by With
+	limiter  *limiter
+	ctx      context.Context
+	minLevel int // verbosity floor of notices
+}
+
+// core is the state shared by a logger and its children so that
+// changes of the parent, by Set functions, config reloads, signals
+// or admin, apply to all of them
+type core struct {
 	levels     map[int]*level
-	verbose    int // if 0, no log
+	verbose    int   // if 0, no log
+	vlevel     int32 // threshold of V, accessed atomically
 	flag       int
 	timeFormat string
 	logFormat  bool
 
-	handlers []Handler
+	clock         Clock
+	deterministic bool
+	dedup         *dedup
+	redactor      *redactor
+	scrubber      *scrubber
+	sanitizer     *sanitizer
+	config        *config
+	elevation     *elevation
+	vmodule       *vmodule
+	limits        map[limitKey]*limitState
+	parallel      bool
+
+	handlers []*handlerEntry
 }
 
 // Handler defines an interface for golog handler
@@ -109,20 +135,24 @@ type Fields struct {
 var defaultLogger *Logger
 
 // Init a default logger with verbose = 3 and
-// output for all levels is stdout with different colors
+// output for all levels is stdout with different colors.
+// Environment variables GOLOG_* override these defaults.
 func init() {
 	defaultLogger = NewLogger()
+	applyEnv()
 }
 
 // NewLogger returns a new instance logger
 // By default, it uses stderr for error and stdout for other levels
 func NewLogger() *Logger {
 	color.NoColor = false
-	logger := &Logger{}
+	logger := &Logger{core: &core{}}
 	logger.verbose = 4
 	logger.flag = 0 // no flag
 	logger.timeFormat = time.RFC3339
 	logger.logFormat = true
+	logger.clock = systemClock{}
+	logger.limits = make(map[limitKey]*limitState)
 
 	logger.levels = make(map[int]*level)
 	for i := FATAL; i <= DEBUG; i++ {
@@ -147,10 +177,18 @@ func NewLogger() *Logger {
 
 // SetVerbosity sets log level. If verbose < NONE, it will be set to NONE.
 // If verbose > DEBUG, it will be set to DEBUG
+// A temporary verbosity set by SetVerbosityFor is cancelled.
 func (l *Logger) SetVerbosity(v int) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
+	l.stopElevation()
+	l.setVerbosity(v)
+}
+
+// setVerbosity sets log level within NONE and DEBUG.
+// Global mutex must be locked by caller.
+func (l *Logger) setVerbosity(v int) {
 	if v < NONE {
 		l.verbose = NONE
 	} else if v > DEBUG {
@@ -162,13 +200,16 @@ func (l *Logger) SetVerbosity(v int) {
 
 // GetVerbosity returns the current log level
 func (l *Logger) GetVerbosity() int {
+	mutex.Lock()
+	defer mutex.Unlock()
+
 	return l.verbose
 }
 
 // SetOutput sets output destination for a specific level
 func (l *Logger) SetOutput(w io.Writer) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	for i := FATAL; i <= DEBUG; i++ {
 		l.levels[i].output = w
@@ -177,92 +218,110 @@ func (l *Logger) SetOutput(w io.Writer) {
 
 // SetLevelOutput sets output destination for a specific level
 func (l *Logger) SetLevelOutput(level int, w io.Writer) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	l.levels[level].output = w
 }
 
+// EnableLevelOutput switches on the output of a specific level
+func (l *Logger) EnableLevelOutput(level int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	l.levels[level].disabled = false
+}
+
+// DisableLevelOutput switches off the output of a specific level.
+// Handlers still receive its message logs.
+func (l *Logger) DisableLevelOutput(level int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	l.levels[level].disabled = true
+}
+
 // SetFlags sets flags for message log output
 func (l *Logger) SetFlags(flag int) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	l.flag = flag
 }
 
 // GetFlags gets flags for message log output
 func (l *Logger) GetFlags() int {
+	mutex.Lock()
+	defer mutex.Unlock()
+
 	return l.flag
 }
 
 // SetTimeFormat sets timestamp with the given format
 func (l *Logger) SetTimeFormat(format string) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	l.timeFormat = format
 }
 
 // EnableColor enables color for all log levels
 func (l *Logger) EnableColor() {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	for i := FATAL; i <= DEBUG; i++ {
-		l.EnableLevelColor(i)
+		l.setLevelColor(i, true)
 	}
 }
 
 // DisableColor disables color for all log levels
 func (l *Logger) DisableColor() {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	for i := FATAL; i <= DEBUG; i++ {
-		l.DisableLevelColor(i)
+		l.setLevelColor(i, false)
 	}
 }
 
 // EnableLevelColor enables color for a specific level
 func (l *Logger) EnableLevelColor(level int) {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	l.levels[level].color = true
-	cf := l.levels[level].colorPrefix
-	cf.EnableColor()
-	//l.levels[level].SetPrefix(cf.SprintFunc()(prefixes[level]))
+	l.setLevelColor(level, true)
 }
 
 // DisableLevelColor enables color for a specific level
 func (l *Logger) DisableLevelColor(level int) {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	l.levels[level].color = false
+	l.setLevelColor(level, false)
+}
+
+// setLevelColor enables or disables color of a level.
+// Global mutex must be locked by caller.
+func (l *Logger) setLevelColor(level int, enabled bool) {
+	l.levels[level].color = enabled
 	cf := l.levels[level].colorPrefix
-	cf.DisableColor()
-	//l.levels[level].SetPrefix(cf.SprintFunc()(prefixes[level]))
+	if enabled {
+		cf.EnableColor()
+	} else {
+		cf.DisableColor()
+	}
 }
 
 // EnableLogFormat enables format log of message.
 // It will print msg as a log with color or prefix etc.
 func (l *Logger) EnableLogFormat() {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	l.EnableColor()
+	for i := FATAL; i <= DEBUG; i++ {
+		l.setLevelColor(i, true)
+	}
 	l.logFormat = true
 }
 
@@ -270,21 +329,21 @@ func (l *Logger) EnableLogFormat() {
 // It will print unformatted msg as normally like with any function printf
 // without color or prefix etc.
 func (l *Logger) DisableLogFormat() {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	l.DisableColor()
+	for i := FATAL; i <= DEBUG; i++ {
+		l.setLevelColor(i, false)
+	}
 	l.logFormat = false
 }
 
 // AddHandler add a new handler in the handler list
 func (l *Logger) AddHandler(h Handler) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	l.handlers = append(l.handlers, h)
+	l.handlers = append(l.handlers, newHandlerEntry(h))
 }
 
 // Debug logs with debug level
@@ -395,27 +454,18 @@ func (l *Logger) Fatalw(msg string, v ...interface{}) {
 // SetVerbosity sets log level. If verbose < NONE, it will be set to NONE.
 // If verbose > DEBUG, it will be set to DEBUG
 func SetVerbosity(v int) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
-
-	if v < NONE {
-		defaultLogger.verbose = NONE
-	} else if v > DEBUG {
-		defaultLogger.verbose = DEBUG
-	} else {
-		defaultLogger.verbose = v
-	}
+	defaultLogger.SetVerbosity(v)
 }
 
 // GetVerbosity returns the current log level
 func GetVerbosity() int {
-	return defaultLogger.verbose
+	return defaultLogger.GetVerbosity()
 }
 
 // SetOutput sets output destination for a specific level
 func SetOutput(w io.Writer) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	for i := FATAL; i <= DEBUG; i++ {
 		defaultLogger.levels[i].output = w
@@ -424,91 +474,88 @@ func SetOutput(w io.Writer) {
 
 // SetLevelOutput sets output destination for a specific level
 func SetLevelOutput(level int, w io.Writer) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	defaultLogger.levels[level].output = w
 }
 
+// EnableLevelOutput switches on the output of a specific level
+func EnableLevelOutput(level int) {
+	defaultLogger.EnableLevelOutput(level)
+}
+
+// DisableLevelOutput switches off the output of a specific level
+func DisableLevelOutput(level int) {
+	defaultLogger.DisableLevelOutput(level)
+}
+
 // SetFlags sets flags for message log output
 func SetFlags(flag int) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	defaultLogger.flag = flag
 }
 
 // GetFlags gets flags for message log output
 func GetFlags() int {
-	return defaultLogger.flag
+	return defaultLogger.GetFlags()
 }
 
 // SetTimeFormat sets timestamp with the given format
 func SetTimeFormat(format string) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	defaultLogger.timeFormat = format
 }
 
 // EnableColor enables color for all log levels
 func EnableColor() {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	for i := FATAL; i <= DEBUG; i++ {
-		defaultLogger.EnableLevelColor(i)
+		defaultLogger.setLevelColor(i, true)
 	}
 }
 
 // DisableColor disables color for all log levels
 func DisableColor() {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
 	for i := FATAL; i <= DEBUG; i++ {
-		defaultLogger.DisableLevelColor(i)
+		defaultLogger.setLevelColor(i, false)
 	}
 }
 
 // EnableLevelColor enables color for a specific level
 func EnableLevelColor(level int) {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
-
-	defaultLogger.levels[level].color = true
-	cf := defaultLogger.levels[level].colorPrefix
-	cf.EnableColor()
+	mutex.Lock()
+	defer mutex.Unlock()
 
+	defaultLogger.setLevelColor(level, true)
 }
 
 // DisableLevelColor enables color for a specific level
 func DisableLevelColor(level int) {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	defaultLogger.levels[level].color = false
-	cf := defaultLogger.levels[level].colorPrefix
-	cf.DisableColor()
+	defaultLogger.setLevelColor(level, false)
 }
 
 // EnableLogFormat enables format log of message.
 // It will print msg as a log with color or prefix etc.
 func EnableLogFormat() {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	EnableColor()
+	for i := FATAL; i <= DEBUG; i++ {
+		defaultLogger.setLevelColor(i, true)
+	}
 	defaultLogger.logFormat = true
 }
 
@@ -516,21 +563,21 @@ func EnableLogFormat() {
 // It will print unformatted msg as normally like with any function printf
 // without color or prefix etc.
 func DisableLogFormat() {
-	if !mutex.IsLocked() {
-		mutex.LockOnce()
-		defer mutex.UnlockOnce()
-	}
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	DisableColor()
+	for i := FATAL; i <= DEBUG; i++ {
+		defaultLogger.setLevelColor(i, false)
+	}
 	defaultLogger.logFormat = false
 }
 
 // AddHandler add a new handler in the handler list
 func AddHandler(h Handler) {
-	mutex.LockOnce()
-	defer mutex.UnlockOnce()
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	defaultLogger.handlers = append(defaultLogger.handlers, h)
+	defaultLogger.handlers = append(defaultLogger.handlers, newHandlerEntry(h))
 }
 
 // Debug logs with debug level
@@ -642,32 +689,133 @@ func Fatalw(msg string, v ...interface{}) {
 // Log wraps print function but using goroutine and waitgroup
 // to have a synchronization of logs.
 func Log(p int, l *Logger, level int, f string, v ...interface{}) {
-	mutex.LockOnce()
+	logDepth(2, p, l, level, f, v...)
+}
+
+// logDepth is the same as Log but the caller reported in message log
+// is the function depth frames above the one calling logDepth.
+func logDepth(depth int, p int, l *Logger, level int, f string, v ...interface{}) {
+	mutex.Lock()
+
+	site := getCallSite(depth + 2)
+	verbose := l.verbose
+	if l.vmodule != nil {
+		verbose = l.vmodule.verbosity(site, verbose)
+	}
+	if verbose < l.minLevel {
+		verbose = l.minLevel
+	}
 
-	caller := getInfoCaller()
+	switch site.hit(level) {
+	case CALLSITEENABLED:
+		verbose = DEBUG
+	case CALLSITEDISABLED:
+		mutex.Unlock()
+		return
+	}
+
+	// Nothing to do if message log is neither printed nor handled
+	if verbose < level && len(l.handlers) == 0 {
+		mutex.Unlock()
+		return
+	}
+
+	skipped := 0
+	if l.limiter != nil {
+		var ok bool
+		if ok, skipped = l.limiter.allow(l.limits, site, l.clock.Now()); !ok {
+			mutex.Unlock()
+			return
+		}
+	}
+
+	// Sampling is done before formatting to minimize cost of dropped logs
+	suppressed := 0
+	if s := l.levels[level].sampler; s != nil {
+		var ok bool
+		if ok, suppressed = s.check(level, site, l.clock.Now()); !ok {
+			mutex.Unlock()
+			return
+		}
+	}
+
+	caller := site.getCaller(!l.deterministic)
 	fields := Fields{}
 
 	fields.Prefix = parsePrefixFields(l, level, caller)
 	fields.Log = parseLogFields(p, l, f, v...)
+	if skipped > 0 {
+		fields.Log = append(fields.Log, &Field{Key: "skipped", Value: strconv.Itoa(skipped)})
+	}
+	if suppressed > 0 {
+		fields.Log = append(fields.Log, &Field{Key: "suppressed", Value: strconv.Itoa(suppressed)})
+	}
+	if l.scrubber != nil {
+		fields.Log = l.scrubber.scrubFields(fields.Log)
+	}
+	if l.sanitizer != nil {
+		fields.Log = l.sanitizer.sanitizeFields(fields.Log)
+	}
 
-	printMsg(p, l, level, fields)
+	defer mutex.Unlock()
 
-	defer mutex.UnlockOnce()
+	// Records hidden by verbosity are only handled and cannot break a run
+	if l.dedup != nil && verbose >= level {
+		ok, summary := l.dedup.check(p, l, verbose, level, fields, caller)
+		summary.dispatch()
+		if !ok {
+			return
+		}
+	}
+
+	dispatch(p, l, verbose, level, fields, caller)
+}
+
+// dispatch prints message log in level output if verbose is high enough
+// and sends it to all handlers. Global mutex must be locked by caller.
+// It is released while handlers are called so that a slow handler
+// does not block other log calls.
+func dispatch(p int, l *Logger, verbose int, level int, fields Fields, caller string) {
+	printMsg(p, l, verbose, level, fields)
+
+	if len(l.handlers) == 0 {
+		return
+	}
 
-	for _, h := range l.handlers {
-		err := h.PrintMsg(p, l, level, fields)
+	handlers := l.handlers
+	parallel := l.parallel
+	ctx := l.ctx
+	clock := l.clock
+
+	var errs []error
+	if parallel && len(handlers) > 1 {
+		// Queued while locked so that handlers receive message logs
+		// in the order of log calls
+		jobs := sendAll(ctx, handlers, p, l, level, fields)
+		mutex.Unlock()
+		errs = waitAll(clock, handlers, jobs)
+	} else {
+		mutex.Unlock()
+		for _, h := range handlers {
+			errs = append(errs, h.printMsg(ctx, clock, p, l, level, fields))
+		}
+	}
+	mutex.Lock()
+
+	// Errors are only printed to avoid recursion into handlers
+	for _, err := range errs {
 		if err != nil {
 			f := Fields{}
 			f.Prefix = parsePrefixFields(l, ERROR, caller)
 			f.Log = parseLogFields(PRINTW, l, "Failed to print message in handler", "err", err)
-			printMsg(PRINTW, l, ERROR, f)
+			printMsg(PRINTW, l, l.verbose, ERROR, f)
 		}
 	}
 }
 
-func printMsg(p int, l *Logger, level int, fields Fields) {
+func printMsg(p int, l *Logger, verbose int, level int, fields Fields) {
 
-	if l.verbose >= level {
+	if verbose >= level && !l.levels[level].disabled {
 		ct := l.levels[level].colorText
 		cf := l.levels[level].colorPrefix
 
@@ -719,17 +867,6 @@ func printMsg(p int, l *Logger, level int, fields Fields) {
 	}
 }
 
-func getInfoCaller() string {
-	if pc, file, line, ok := runtime.Caller(3); ok {
-		fn := runtime.FuncForPC(pc).Name()
-		arr := strings.Split(path.Base(fn), ".")
-		str := fmt.Sprintf("%s:%d:%s", path.Base(file), line, arr[len(arr)-1])
-		return str
-	}
-
-	return ""
-}
-
 func getTimeNow(format string) string {
 	return time.Now().Format(format)
 }
@@ -749,7 +886,7 @@ func parsePrefixFields(l *Logger, level int, caller string) []*Field {
 	if l.flag&FTIMESTAMP != 0 {
 		field := &Field{
 			Key:   "ts",
-			Value: time.Now().Format(l.timeFormat),
+			Value: l.clock.Now().Format(l.timeFormat),
 		}
 		fields = append(fields, field)
 	}
@@ -804,37 +941,72 @@ func parseLogFields(p int, l *Logger, f string, kv ...interface{}) []*Field {
 		}
 		fields = append(fields, field)
 
-		if len(kv)%2 != 0 {
-			kv = append(kv, "missing")
+		// static fields come before user key/value fields
+		fields = append(fields, l.fields...)
+		fields = append(fields, parseKeyValues(l, kv...)...)
+		break
+	}
+
+	if p != PRINTW {
+		fields = append(fields, l.fields...)
+	}
+
+	return fields
+}
+
+// parseKeyValues converts a list of key/value pairs into log fields
+// redacting sensitive values according to logger policy
+func parseKeyValues(l *Logger, kv ...interface{}) []*Field {
+	var fields []*Field
+
+	if len(kv)%2 != 0 {
+		kv = append(kv, "missing")
+	}
+
+	// if no key/value fields, return line after print message
+
+	for i := 0; i < len(kv); i += 2 {
+		// cast 1st elem = key to string
+		k := cast.ToString(kv[i])
+		if k == "" {
+			k = "missing"
 		}
 
-		// if no key/value fields, return line after print message
+		// cast 2nd elem = value
+		var val string
+		v := kv[i+1]
+		if l.redactor != nil && l.redactor.match(k) {
+			v = l.redactor.redact(v)
+		}
 
-		for i := 0; i < len(kv); i += 2 {
-			// cast 1st elem = key to string
-			k := cast.ToString(kv[i])
-			if k == "" {
-				k = "missing"
+		rv := reflect.ValueOf(v)
+		kind := rv.Kind()
+		if kind != reflect.String && containsSecret(rv, 0) {
+			// fmt does not mask secrets of unexported fields
+			r := l.redactor
+			if r == nil {
+				r = &redactor{}
 			}
-
-			// cast 2nd elem = value
-			var val string
-			v := kv[i+1]
-			kind := reflect.ValueOf(v).Kind()
-			if kind == reflect.Map || kind == reflect.Struct || kind == reflect.Ptr {
-				val = fmt.Sprintf("%+v", v)
-			} else if kind == reflect.String || kind == reflect.Array || kind == reflect.Slice {
-				val = fmt.Sprintf("%q", v)
+			val = r.format(rv, 0)
+		} else if l.redactor != nil && (kind == reflect.Slice || kind == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8 {
+			// elements may hold keys to redact
+			val = l.redactor.format(rv, 0)
+		} else if kind == reflect.Map || kind == reflect.Struct || kind == reflect.Ptr {
+			if l.redactor != nil {
+				val = l.redactor.format(reflect.ValueOf(v), 0)
 			} else {
-				val = fmt.Sprintf("%v", v)
-			}
-			field := &Field{
-				Key:   k,
-				Value: val,
+				val = fmt.Sprintf("%+v", v)
 			}
-			fields = append(fields, field)
+		} else if kind == reflect.String || kind == reflect.Array || kind == reflect.Slice {
+			val = fmt.Sprintf("%q", v)
+		} else {
+			val = fmt.Sprintf("%v", v)
 		}
-		break
+		field := &Field{
+			Key:   k,
+			Value: val,
+		}
+		fields = append(fields, field)
 	}
 
 	return fields
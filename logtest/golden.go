@@ -0,0 +1,62 @@
+package logtest
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateFlag is the name of the test flag rewriting golden files
+// with the current output instead of comparing them
+const UpdateFlag = "update"
+
+func init() {
+	if flag.Lookup(UpdateFlag) == nil {
+		flag.Bool(UpdateFlag, false, "update golden files in testdata")
+	}
+}
+
+// AssertGolden compares output rendered by a logger with the content
+// of testdata/<name>.golden. With -update flag, the golden file
+// is written with output instead.
+func AssertGolden(tb testing.TB, name string, output []byte) bool {
+	tb.Helper()
+
+	file := filepath.Join("testdata", name+".golden")
+
+	if update() {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			tb.Fatalf("cannot create testdata directory: %s", err)
+		}
+
+		if err := ioutil.WriteFile(file, output, 0644); err != nil {
+			tb.Fatalf("cannot update golden file %s: %s", file, err)
+		}
+
+		return true
+	}
+
+	want, err := ioutil.ReadFile(file)
+	if err != nil {
+		tb.Fatalf("cannot read golden file %s: %s", file, err)
+	}
+
+	if !bytes.Equal(want, output) {
+		tb.Errorf("\nwant:\n%s\nhave:\n%s", want, output)
+		return false
+	}
+
+	return true
+}
+
+func update() bool {
+	f := flag.Lookup(UpdateFlag)
+	if f == nil {
+		return false
+	}
+
+	return f.Value.String() == "true"
+}
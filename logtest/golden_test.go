@@ -0,0 +1,27 @@
+package logtest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/uthng/golog"
+)
+
+func TestAssertGolden(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetVerbosity(golog.DEBUG)
+	logger.SetFlags(golog.FTIMESTAMP | golog.FCALLER)
+	logger.EnableDeterministic()
+
+	logger.Debugf("This is %s log\n", "debug")
+	logger.Infow("This is info log", "key", "value", "count", 3)
+
+	logger.SetFlags(golog.FTIMESTAMP | golog.FCALLER | golog.FFULLSTRUCTUREDLOG)
+	logger.Warnw("This is warn log", "key", "value")
+	logger.Errorln("This is error log")
+
+	AssertGolden(t, "deterministic", buf.Bytes())
+}
@@ -0,0 +1,181 @@
+// Package logtest provides a golog logger capturing messages in memory
+// and helpers to make assertions on them in tests.
+package logtest
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/uthng/golog"
+)
+
+// Entry is a message log captured by a Recorder
+type Entry struct {
+	Level  int
+	Msg    string
+	Caller string
+	Fields map[string]string
+}
+
+// Recorder is a golog handler storing all message logs in memory
+type Recorder struct {
+	entries []Entry
+	mutex   sync.Mutex
+}
+
+// writer routes log output to testing.TB
+type writer struct {
+	tb testing.TB
+}
+
+// New returns a logger with DEBUG verbosity whose output goes to t.Log
+// and a Recorder capturing all its message logs. The logger is closed
+// when the test ends.
+func New(tb testing.TB) (*golog.Logger, *Recorder) {
+	r := &Recorder{}
+
+	logger := golog.NewLogger()
+	logger.SetVerbosity(golog.DEBUG)
+	logger.SetFlags(golog.FCALLER)
+	logger.SetOutput(NewWriter(tb))
+	logger.DisableColor()
+	logger.AddHandler(r)
+	tb.Cleanup(logger.Close)
+
+	return logger, r
+}
+
+// NewWriter returns a writer logging with tb.Log so that
+// output is only shown when the test fails or with -v
+func NewWriter(tb testing.TB) io.Writer {
+	return &writer{tb: tb}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.tb.Helper()
+	w.tb.Log(strings.TrimRight(string(p), "\n"))
+
+	return len(p), nil
+}
+
+// PrintMsg records message log. As handlers, it receives message logs
+// of all levels whatever the verbosity of the logger.
+func (r *Recorder) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	e := Entry{
+		Level:  level,
+		Fields: make(map[string]string),
+	}
+
+	for _, f := range fields.Prefix {
+		if f.Key == "caller" {
+			e.Caller = f.Value
+		}
+	}
+
+	for _, f := range fields.Log {
+		if f.Key == "msg" && e.Msg == "" {
+			e.Msg = strings.TrimRight(f.Value, "\n")
+			continue
+		}
+		e.Fields[f.Key] = unquote(f.Value)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = append(r.entries, e)
+
+	return nil
+}
+
+// Entries returns a copy of all captured entries
+func (r *Recorder) Entries() []Entry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Entry{}, r.entries...)
+}
+
+// Reset removes all captured entries
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = nil
+}
+
+// FilterByLevel returns entries logged with the given level
+func (r *Recorder) FilterByLevel(level int) []Entry {
+	var entries []Entry
+
+	for _, e := range r.Entries() {
+		if e.Level == level {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// FilterByField returns entries having the field key with the given value
+func (r *Recorder) FilterByField(key string, value interface{}) []Entry {
+	var entries []Entry
+
+	for _, e := range r.Entries() {
+		if v, ok := e.Fields[key]; ok && v == fmt.Sprint(value) {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// AssertLogged checks that a message log with the given level, message
+// and key/value fields has been captured. Otherwise, the test fails.
+func (r *Recorder) AssertLogged(tb testing.TB, level int, msg string, kv ...interface{}) bool {
+	tb.Helper()
+
+	for _, e := range r.FilterByLevel(level) {
+		if e.Msg == msg && e.hasFields(kv...) {
+			return true
+		}
+	}
+
+	var logged []string
+	for _, e := range r.Entries() {
+		logged = append(logged, fmt.Sprintf("%d %q %v", e.Level, e.Msg, e.Fields))
+	}
+
+	tb.Errorf("\nwant:\n%d %q %v\nhave:\n%s", level, msg, kv, strings.Join(logged, "\n"))
+
+	return false
+}
+
+func (e Entry) hasFields(kv ...interface{}) bool {
+	for i := 0; i < len(kv); i += 2 {
+		k := fmt.Sprint(kv[i])
+		v, ok := e.Fields[k]
+		if !ok {
+			return false
+		}
+
+		if i+1 < len(kv) && v != fmt.Sprint(kv[i+1]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// unquote removes quotes added by golog to string values
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+
+	return s
+}
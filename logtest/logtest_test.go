@@ -0,0 +1,66 @@
+package logtest
+
+import (
+	"testing"
+
+	"github.com/uthng/golog"
+)
+
+func TestRecorder(t *testing.T) {
+	logger, rec := New(t)
+	logger.SetVerbosity(golog.INFO)
+
+	logger.Debugw("This is debug log", "key", "value")
+	logger.Infow("This is info log", "user", "john doe", "count", 3)
+	logger.Errorf("This is %s log\n", "error")
+	logger.With("component", "payments").Errorw("Payment failed", "amount", 15.5)
+
+	// Entries are recorded whatever the verbosity
+	if n := len(rec.Entries()); n != 4 {
+		t.Fatalf("\nwant:\n4 entries\nhave:\n%d", n)
+	}
+
+	rec.AssertLogged(t, golog.DEBUG, "This is debug log", "key", "value")
+	rec.AssertLogged(t, golog.INFO, "This is info log", "user", "john doe", "count", 3)
+	rec.AssertLogged(t, golog.ERROR, "This is error log")
+	rec.AssertLogged(t, golog.ERROR, "Payment failed", "component", "payments", "amount", 15.5)
+
+	if n := len(rec.FilterByLevel(golog.ERROR)); n != 2 {
+		t.Errorf("\nwant:\n2 error entries\nhave:\n%d", n)
+	}
+
+	entries := rec.FilterByField("component", "payments")
+	if len(entries) != 1 || entries[0].Msg != "Payment failed" {
+		t.Errorf("\nwant:\nPayment failed\nhave:\n%v", entries)
+	}
+
+	if entries[0].Caller != "logtest_test.go:16:TestRecorder" {
+		t.Errorf("\nwant:\nlogtest_test.go:16:TestRecorder\nhave:\n%s", entries[0].Caller)
+	}
+
+	rec.Reset()
+	if n := len(rec.Entries()); n != 0 {
+		t.Errorf("\nwant:\n0 entries\nhave:\n%d", n)
+	}
+}
+
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.failed = true
+}
+
+func TestAssertLoggedFailure(t *testing.T) {
+	logger, rec := New(t)
+	logger.Infow("This is info log", "key", "value")
+
+	ft := &fakeTB{TB: t}
+	if rec.AssertLogged(ft, golog.INFO, "This is info log", "key", "other") || !ft.failed {
+		t.Errorf("AssertLogged should fail with wrong field value")
+	}
+}
@@ -0,0 +1,141 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// RecoverOptions defines options of Recover
+type RecoverOptions struct {
+	// Level is the level of the panic record: FATAL or ERROR (default)
+	Level int
+	// Message is the message of the panic record.
+	// By default: "Recovered from panic"
+	Message string
+	// Repanic panics again with the same value once the record is flushed
+	Repanic bool
+	// Exit exits with status 2, as an unrecovered panic, once
+	// the record is flushed. Ignored if Repanic is set.
+	Exit bool
+}
+
+// Flusher is implemented by handlers buffering message logs.
+// Flush is called by Logger.Flush.
+type Flusher interface {
+	Flush() error
+}
+
+// Recover recovers a panic and logs it with the panic value and stack
+// as fields. It must be deferred directly: defer l.Recover(nil).
+// The record is dispatched to all handlers before returning.
+func (l *Logger) Recover(opts *RecoverOptions) {
+	// recover only works if called by the deferred function itself
+	if r := recover(); r != nil {
+		l.recovered(r, panicDepth()+1, opts)
+	}
+}
+
+// recovered logs the panic value r raised by the function at depth
+func (l *Logger) recovered(r interface{}, depth int, opts *RecoverOptions) {
+	o := RecoverOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.Level != FATAL {
+		o.Level = ERROR
+	}
+
+	if o.Message == "" {
+		o.Message = "Recovered from panic"
+	}
+
+	logDepth(depth, PRINTW, l, o.Level, o.Message,
+		"panic", fmt.Sprint(r),
+		"stack", string(debug.Stack()))
+	l.Flush()
+
+	if o.Repanic {
+		panic(r)
+	}
+
+	if o.Exit {
+		os.Exit(2)
+	}
+}
+
+// Go runs f in a new goroutine recovering and logging
+// its panics with error level
+func (l *Logger) Go(f func()) {
+	l.GoWith(f, nil)
+}
+
+// GoWith runs f in a new goroutine recovering and logging
+// its panics with the given options
+func (l *Logger) GoWith(f func(), opts *RecoverOptions) {
+	go func() {
+		defer l.Recover(opts)
+		f()
+	}()
+}
+
+// Flush dispatches pending message logs and flushes handlers
+// implementing Flusher
+func (l *Logger) Flush() {
+	mutex.Lock()
+	if l.dedup != nil {
+		l.dedup.flush().dispatch()
+	}
+	handlers := l.handlers
+	mutex.Unlock()
+
+	for _, h := range handlers {
+		if f, ok := h.handler.(Flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
+// Recover recovers a panic and logs it with the default logger.
+// It must be deferred directly: defer golog.Recover(nil).
+func Recover(opts *RecoverOptions) {
+	if r := recover(); r != nil {
+		defaultLogger.recovered(r, panicDepth()+1, opts)
+	}
+}
+
+// Go runs f in a new goroutine recovering and logging
+// its panics with the default logger
+func Go(f func()) {
+	defaultLogger.Go(f)
+}
+
+// GoWith runs f in a new goroutine recovering and logging
+// its panics with the default logger and the given options
+func GoWith(f func(), opts *RecoverOptions) {
+	defaultLogger.GoWith(f, opts)
+}
+
+// Flush flushes the default logger
+func Flush() {
+	defaultLogger.Flush()
+}
+
+// panicDepth returns the depth of the function which has panicked
+// relatively to the deferred function calling panicDepth. Frames
+// of the runtime handling the panic are skipped.
+func panicDepth() int {
+	for i := 2; ; i++ {
+		pc, _, _, ok := runtime.Caller(i)
+		if !ok {
+			return 1
+		}
+
+		if f := runtime.FuncForPC(pc); f == nil || !strings.HasPrefix(f.Name(), "runtime.") {
+			return i - 1
+		}
+	}
+}
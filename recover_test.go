@@ -0,0 +1,88 @@
+package golog_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+// notifyHandler signals each message log once previous handlers are called
+type notifyHandler struct {
+	ch chan struct{}
+}
+
+func (h notifyHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	h.ch <- struct{}{}
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+
+	recorder := &memoryHandler{}
+	logged := make(chan struct{}, 2)
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetFlags(golog.FCALLER)
+	logger.AddHandler(recorder)
+	logger.AddHandler(notifyHandler{ch: logged})
+
+	func() {
+		defer logger.Recover(nil)
+		panic("boom")
+	}()
+
+	logger.GoWith(func() {
+		var m map[string]int
+		m["key"] = 1
+	}, &golog.RecoverOptions{Level: golog.FATAL, Message: "Worker crashed"})
+
+	// Wait for the panic of the goroutine to be logged
+	<-logged
+	<-logged
+
+	output := []string{
+		`recover_test.go:36:func1 ERROR:[ ]+Recovered from panic[ ]+panic="boom" stack=".*goroutine.*"$`,
+		`recover_test.go:41:func2 FATAL:[ ]+Worker crashed[ ]+panic="assignment to entry in nil map" stack=".*"$`,
+	}
+
+	lines := strings.Split(strings.TrimRight(utils.StringStripAnsi(buf.String()), "\n"), "\n")
+	if len(lines) != len(output) {
+		t.Fatalf("\nwant:\n%d lines\nhave:\n%s", len(output), buf.String())
+	}
+
+	for idx, w := range output {
+		matched, _ := regexp.MatchString(w, lines[idx])
+		if !matched {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", w, lines[idx])
+		}
+	}
+
+	if len(recorder.msgs) != 2 {
+		t.Errorf("\nwant:\n2 messages in handler\nhave:\n%d", len(recorder.msgs))
+	}
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("\nwant:\nboom\nhave:\n%v", r)
+		}
+
+		if !strings.Contains(buf.String(), "Recovered from panic") {
+			t.Errorf("\nwant:\nRecovered from panic\nhave:\n%s", buf.String())
+		}
+	}()
+
+	defer logger.Recover(&golog.RecoverOptions{Repanic: true})
+	panic("boom")
+}
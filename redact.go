@@ -0,0 +1,250 @@
+package golog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// REDACTMASK replaces sensitive values with a mask
+	REDACTMASK = iota
+	// REDACTHASH replaces sensitive values with their sha256 hash
+	REDACTHASH
+)
+
+// Mask is the string rendered in place of sensitive values
+const Mask = "****"
+
+// maxRedactDepth limits recursion in nested values
+const maxRedactDepth = 10
+
+// DefaultRedactPatterns are key patterns commonly holding sensitive values
+var DefaultRedactPatterns = []string{
+	"password",
+	"passwd",
+	"token",
+	"*_token",
+	"authorization",
+	"secret",
+	"*_secret",
+	"api_key",
+	"apikey",
+}
+
+// Secret is a string always rendered masked in message logs,
+// even in unexported fields of structs
+type Secret string
+
+var secretType = reflect.TypeOf(Secret(""))
+
+type redactor struct {
+	mode     int
+	patterns []string
+}
+
+// String returns the mask instead of the secret
+func (s Secret) String() string {
+	return Mask
+}
+
+// GoString returns the mask instead of the secret
+func (s Secret) GoString() string {
+	return Mask
+}
+
+// MarshalJSON returns the mask instead of the secret
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Mask + `"`), nil
+}
+
+// SetRedaction masks or hashes, according to mode, values of fields whose
+// key matches one of the given glob patterns (case insensitive) before
+// message logs are printed or sent to handlers. Keys of nested maps
+// and structs are also checked. Without pattern, redaction is disabled.
+func (l *Logger) SetRedaction(mode int, patterns ...string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if len(patterns) == 0 {
+		l.redactor = nil
+		return
+	}
+
+	r := &redactor{mode: mode}
+	for _, p := range patterns {
+		r.patterns = append(r.patterns, strings.ToLower(p))
+	}
+	l.redactor = r
+}
+
+// SetRedaction sets redaction policy of the default logger
+func SetRedaction(mode int, patterns ...string) {
+	defaultLogger.SetRedaction(mode, patterns...)
+}
+
+// match returns if the key holds a sensitive value
+func (r *redactor) match(key string) bool {
+	k := strings.ToLower(key)
+	for _, p := range r.patterns {
+		if ok, _ := path.Match(p, k); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redact returns the value to log in place of a sensitive one
+func (r *redactor) redact(v interface{}) interface{} {
+	if r.mode == REDACTHASH {
+		sum := sha256.Sum256([]byte(fmt.Sprint(v)))
+		return "sha256:" + hex.EncodeToString(sum[:])[:16]
+	}
+
+	return Secret("")
+}
+
+// format renders v as fmt with %+v does but with
+// sensitive values of nested maps and structs redacted
+func (r *redactor) format(v reflect.Value, depth int) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+
+	if depth > maxRedactDepth {
+		return "..."
+	}
+
+	// Unexported fields cannot use methods of Secret
+	if v.Type() == secretType {
+		return Mask
+	}
+
+	if v.CanInterface() {
+		switch i := v.Interface().(type) {
+		case fmt.Stringer, error:
+			return fmt.Sprintf("%+v", i)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return "<nil>"
+		}
+		return r.format(v.Elem(), depth+1)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "<nil>"
+		}
+		if k := v.Elem().Kind(); k == reflect.Struct || k == reflect.Map {
+			return "&" + r.format(v.Elem(), depth+1)
+		}
+	case reflect.Map:
+		var pairs []string
+		for _, k := range v.MapKeys() {
+			key := r.format(k, depth+1)
+			val := r.formatField(key, v.MapIndex(k), depth)
+			pairs = append(pairs, key+":"+val)
+		}
+		sort.Strings(pairs)
+		return "map[" + strings.Join(pairs, " ") + "]"
+	case reflect.Struct:
+		var pairs []string
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			name := t.Field(i).Name
+			val := r.formatField(name, v.Field(i), depth)
+			pairs = append(pairs, name+":"+val)
+		}
+		return "{" + strings.Join(pairs, " ") + "}"
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return "[]"
+		}
+		var elems []string
+		for i := 0; i < v.Len(); i++ {
+			elems = append(elems, r.format(v.Index(i), depth+1))
+		}
+		return "[" + strings.Join(elems, " ") + "]"
+	}
+
+	return formatLeaf(v)
+}
+
+// containsSecret returns if v holds a Secret, in nested values included
+func containsSecret(v reflect.Value, depth int) bool {
+	if !v.IsValid() || depth > maxRedactDepth {
+		return false
+	}
+
+	if v.Type() == secretType {
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		return !v.IsNil() && containsSecret(v.Elem(), depth+1)
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if containsSecret(iter.Key(), depth+1) || containsSecret(iter.Value(), depth+1) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if containsSecret(v.Field(i), depth+1) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if containsSecret(v.Index(i), depth+1) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// formatField renders the value of a nested field, redacted if needed
+func (r *redactor) formatField(key string, v reflect.Value, depth int) string {
+	if !r.match(key) {
+		return r.format(v, depth+1)
+	}
+
+	if v.CanInterface() {
+		return fmt.Sprint(r.redact(v.Interface()))
+	}
+
+	return fmt.Sprint(r.redact(formatLeaf(v)))
+}
+
+// formatLeaf renders a scalar value even if it comes from an unexported field
+func formatLeaf(v reflect.Value) string {
+	if v.CanInterface() {
+		return fmt.Sprintf("%+v", v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return fmt.Sprint(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fmt.Sprint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Float())
+	}
+
+	return "?"
+}
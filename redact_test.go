@@ -0,0 +1,138 @@
+package golog_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+type credentials struct {
+	User     string
+	Password string
+	Extra    map[string]interface{}
+}
+
+type creds struct {
+	user string
+	pass golog.Secret
+}
+
+func TestRedaction(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mode     int
+		patterns []string
+		kv       []interface{}
+		output   string
+	}{
+		{
+			"Disabled",
+			golog.REDACTMASK,
+			nil,
+			[]interface{}{"password", "p4ss", "key", golog.Secret("s3cr3t")},
+			`password="p4ss" key="\*\*\*\*"$`,
+		},
+		{
+			"MaskKey",
+			golog.REDACTMASK,
+			golog.DefaultRedactPatterns,
+			[]interface{}{"user", "john", "Password", "p4ss", "client_secret", 42},
+			`user="john" Password="\*\*\*\*" client_secret="\*\*\*\*"$`,
+		},
+		{
+			"HashKey",
+			golog.REDACTHASH,
+			[]string{"token"},
+			[]interface{}{"token", "abc"},
+			`token="sha256:ba7816bf8f01cfea"$`,
+		},
+		{
+			"Nested",
+			golog.REDACTMASK,
+			golog.DefaultRedactPatterns,
+			[]interface{}{"creds", credentials{"john", "p4ss", map[string]interface{}{"api_key": "k", "port": 80}}},
+			`creds={User:john Password:\*\*\*\* Extra:map\[api_key:\*\*\*\* port:80\]}$`,
+		},
+		{
+			"NestedPointer",
+			golog.REDACTMASK,
+			golog.DefaultRedactPatterns,
+			[]interface{}{"creds", &credentials{User: "john", Password: "p4ss"}},
+			`creds=&{User:john Password:\*\*\*\* Extra:map\[\]}$`,
+		},
+		{
+			"UnexportedSecret",
+			golog.REDACTMASK,
+			golog.DefaultRedactPatterns,
+			[]interface{}{"creds", creds{user: "john", pass: golog.Secret("hunter2")}},
+			`creds={user:john pass:\*\*\*\*}$`,
+		},
+		{
+			"UnexportedSecretDisabled",
+			golog.REDACTMASK,
+			nil,
+			[]interface{}{"creds", []creds{{user: "john", pass: golog.Secret("hunter2")}}},
+			`creds=\[{user:john pass:\*\*\*\*}\]$`,
+		},
+		{
+			"SliceOfMaps",
+			golog.REDACTMASK,
+			golog.DefaultRedactPatterns,
+			[]interface{}{"list", []map[string]string{{"password": "hunter2"}}},
+			`list=\[map\[password:\*\*\*\*\]\]$`,
+		},
+		{
+			"SliceOfPointers",
+			golog.REDACTMASK,
+			golog.DefaultRedactPatterns,
+			[]interface{}{"users", []*credentials{{User: "john", Password: "hunter2"}}},
+			`users=\[&{User:john Password:\*\*\*\* Extra:map\[\]}\]$`,
+		},
+		{
+			"Array",
+			golog.REDACTMASK,
+			golog.DefaultRedactPatterns,
+			[]interface{}{"users", [1]credentials{{User: "john", Password: "hunter2"}}},
+			`users=\[{User:john Password:\*\*\*\* Extra:map\[\]}\]$`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			logger := golog.NewLogger()
+			logger.SetOutput(&buf)
+			logger.SetRedaction(tc.mode, tc.patterns...)
+
+			logger.Infow("Login", tc.kv...)
+
+			msg := strings.TrimRight(utils.StringStripAnsi(buf.String()), "\n")
+			matched, _ := regexp.MatchString(tc.output, msg)
+			if !matched {
+				t.Errorf("\nwant:\n%s\nhave:\n%s", tc.output, msg)
+			}
+			if strings.Contains(msg, "hunter2") || strings.Contains(msg, "p4ss") && tc.patterns != nil {
+				t.Errorf("secret leaked in %s", msg)
+			}
+		})
+	}
+}
+
+func TestRedactionWith(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetRedaction(golog.REDACTMASK, "authorization")
+
+	logger.With("Authorization", "Bearer xyz").Infow("Request")
+
+	if strings.Contains(buf.String(), "xyz") {
+		t.Errorf("secret leaked in %s", buf.String())
+	}
+}
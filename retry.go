@@ -0,0 +1,307 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryOptions defines options of a retry handler
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of calls per message log (default 5)
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry (default 100ms).
+	// It is doubled at each retry with jitter.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries (default 30s)
+	MaxBackoff time.Duration
+	// QueueSize is the number of message logs waiting to be sent (default 1000).
+	// Message logs are rejected when the queue is full.
+	QueueSize int
+	// Threshold is the number of consecutive failures opening
+	// the circuit breaker (default 5)
+	Threshold int
+	// BreakerInterval is the duration during which the circuit breaker
+	// stays open before a probe (default 30s)
+	BreakerInterval time.Duration
+}
+
+// RetryStats gives state and counters of a retry handler
+type RetryStats struct {
+	State     string `json:"state"`
+	Queued    int    `json:"queued"`
+	Delivered uint64 `json:"delivered"`
+	Retries   uint64 `json:"retries"`
+	Dropped   uint64 `json:"dropped"`
+	Rejected  uint64 `json:"rejected"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// HTTPError is returned by handlers when an HTTP endpoint responds
+// with an error status. It is used by retry handlers to know if the
+// error is transient and when to retry.
+type HTTPError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+// RetryHandler sends message logs to a handler in background,
+// retrying failed calls
+type RetryHandler struct {
+	handler Handler
+	opts    RetryOptions
+	breaker *breaker
+
+	queue   chan retryRecord
+	stop    chan struct{}
+	done    chan struct{}
+	pending int
+	cond    *sync.Cond
+
+	delivered uint64
+	retries   uint64
+	dropped   uint64
+	rejected  uint64
+	lastError atomic.Value
+
+	closeOnce sync.Once
+}
+
+type retryRecord struct {
+	p      int
+	logger *Logger
+	level  int
+	fields Fields
+}
+
+// NewHTTPError returns an HTTPError from a response.
+// Retry-After header is read as seconds or as HTTP date.
+func NewHTTPError(resp *http.Response) *HTTPError {
+	e := &HTTPError{StatusCode: resp.StatusCode}
+
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if s, err := strconv.Atoi(v); err == nil && s > 0 {
+			e.RetryAfter = time.Duration(s) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil {
+			e.RetryAfter = time.Until(t)
+		}
+	}
+
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Temporary returns if the request may succeed later
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout
+}
+
+// NewRetry returns a handler queuing message logs and sending them to h
+// in background so that the caller is never blocked. Failed calls are retried
+// with exponential backoff and jitter. With an HTTPError 429 or 503, its
+// RetryAfter is used as delay, bounded by MaxBackoff. Errors which are not temporary are not retried.
+// After Threshold consecutive failures, a circuit breaker rejects message logs
+// until a probe succeeds.
+func NewRetry(h Handler, opts *RetryOptions) *RetryHandler {
+	o := RetryOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = 100 * time.Millisecond
+	}
+
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 30 * time.Second
+	}
+
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1000
+	}
+
+	if o.Threshold <= 0 {
+		o.Threshold = 5
+	}
+
+	if o.BreakerInterval <= 0 {
+		o.BreakerInterval = 30 * time.Second
+	}
+
+	r := &RetryHandler{
+		handler: h,
+		opts:    o,
+		breaker: newBreaker(o.Threshold, o.BreakerInterval),
+		queue:   make(chan retryRecord, o.QueueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		cond:    sync.NewCond(&sync.Mutex{}),
+	}
+
+	go r.run()
+
+	return r
+}
+
+// PrintMsg queues message log. An error is returned if the
+// circuit breaker is open or the queue is full.
+func (r *RetryHandler) PrintMsg(p int, l *Logger, level int, fields Fields) error {
+	if r.breaker.rejects(time.Now()) {
+		atomic.AddUint64(&r.rejected, 1)
+		return errors.New("retry handler: circuit breaker open")
+	}
+
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+
+	select {
+	case <-r.stop:
+		return errors.New("retry handler: closed")
+	default:
+	}
+
+	select {
+	case r.queue <- retryRecord{p: p, logger: l, level: level, fields: fields}:
+		r.pending++
+		return nil
+	default:
+		atomic.AddUint64(&r.rejected, 1)
+		return errors.New("retry handler: queue full")
+	}
+}
+
+// Flush waits until all queued message logs are sent or dropped
+func (r *RetryHandler) Flush() error {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+
+	for r.pending > 0 {
+		r.cond.Wait()
+	}
+
+	return nil
+}
+
+// Close stops sending message logs. Queued message logs are dropped.
+func (r *RetryHandler) Close() {
+	r.closeOnce.Do(func() {
+		r.cond.L.Lock()
+		close(r.stop)
+		r.cond.L.Unlock()
+		<-r.done
+	})
+}
+
+// Stats returns state of the circuit breaker and counters
+func (r *RetryHandler) Stats() RetryStats {
+	s := RetryStats{
+		State:     breakerStateNames[r.breaker.getState()],
+		Queued:    len(r.queue),
+		Delivered: atomic.LoadUint64(&r.delivered),
+		Retries:   atomic.LoadUint64(&r.retries),
+		Dropped:   atomic.LoadUint64(&r.dropped),
+		Rejected:  atomic.LoadUint64(&r.rejected),
+	}
+
+	if err, ok := r.lastError.Load().(string); ok {
+		s.LastError = err
+	}
+
+	return s
+}
+
+func (r *RetryHandler) run() {
+	defer close(r.done)
+
+	for {
+		select {
+		case <-r.stop:
+			n := 0
+			for len(r.queue) > 0 {
+				<-r.queue
+				n++
+			}
+			atomic.AddUint64(&r.dropped, uint64(n))
+			r.processed(n)
+			return
+		case rec := <-r.queue:
+			if !r.send(rec) {
+				atomic.AddUint64(&r.dropped, 1)
+			}
+			r.processed(1)
+		}
+	}
+}
+
+// processed removes n message logs from pending ones
+func (r *RetryHandler) processed(n int) {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+
+	r.pending -= n
+	r.cond.Broadcast()
+}
+
+// send calls the handler until it succeeds, the error is not temporary,
+// the circuit breaker opens or attempts are exhausted
+func (r *RetryHandler) send(rec retryRecord) bool {
+	backoff := r.opts.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		if !r.breaker.allow(time.Now()) {
+			return false
+		}
+
+		err := r.handler.PrintMsg(rec.p, rec.logger, rec.level, rec.fields)
+		r.breaker.record(err, time.Now())
+		if err == nil {
+			atomic.AddUint64(&r.delivered, 1)
+			return true
+		}
+		r.lastError.Store(err.Error())
+
+		var temporary interface{ Temporary() bool }
+		if errors.As(err, &temporary) && !temporary.Temporary() {
+			return false
+		}
+
+		if attempt >= r.opts.MaxAttempts {
+			return false
+		}
+
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 &&
+			(httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode == http.StatusServiceUnavailable) {
+			delay = httpErr.RetryAfter
+			if delay > r.opts.MaxBackoff {
+				delay = r.opts.MaxBackoff
+			}
+		}
+
+		backoff *= 2
+		if backoff > r.opts.MaxBackoff {
+			backoff = r.opts.MaxBackoff
+		}
+
+		atomic.AddUint64(&r.retries, 1)
+		select {
+		case <-time.After(delay):
+		case <-r.stop:
+			return false
+		}
+	}
+}
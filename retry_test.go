@@ -0,0 +1,133 @@
+package golog_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+)
+
+// flakyHandler returns the given errors before succeeding
+type flakyHandler struct {
+	errs  []error
+	calls []time.Time
+	msgs  []string
+	mutex sync.Mutex
+}
+
+func (h *flakyHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.calls = append(h.calls, time.Now())
+	if len(h.errs) > 0 {
+		err := h.errs[0]
+		h.errs = h.errs[1:]
+		return err
+	}
+	h.msgs = append(h.msgs, fields.Log[0].Value)
+
+	return nil
+}
+
+func TestRetryHandler(t *testing.T) {
+	flaky := &flakyHandler{errs: []error{
+		errors.New("connection refused"),
+		&golog.HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 50 * time.Millisecond},
+		&golog.HTTPError{StatusCode: http.StatusBadRequest},
+	}}
+
+	retry := golog.NewRetry(flaky, &golog.RetryOptions{InitialBackoff: time.Millisecond})
+	defer retry.Close()
+
+	logger := golog.NewLogger()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHandler(retry)
+
+	logger.Infow("msg 1")
+	logger.Infow("msg 2")
+	logger.Flush()
+
+	if msgs := []string{"msg 2"}; !reflect.DeepEqual(flaky.msgs, msgs) {
+		t.Errorf("\nwant:\n%v\nhave:\n%v", msgs, flaky.msgs)
+	}
+
+	if d := flaky.calls[2].Sub(flaky.calls[1]); d < 50*time.Millisecond {
+		t.Errorf("\nwant:\nRetry-After honored\nhave:\n%s", d)
+	}
+
+	have := retry.Stats()
+	want := golog.RetryStats{State: "closed", Delivered: 1, Retries: 2, Dropped: 1, LastError: "http status 400 Bad Request"}
+	if have != want {
+		t.Errorf("\nwant:\n%+v\nhave:\n%+v", want, have)
+	}
+}
+
+func TestRetryHandlerMaxBackoff(t *testing.T) {
+	flaky := &flakyHandler{errs: []error{
+		&golog.HTTPError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Hour},
+	}}
+
+	retry := golog.NewRetry(flaky, &golog.RetryOptions{MaxBackoff: 10 * time.Millisecond})
+	defer retry.Close()
+
+	logger := golog.NewLogger()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHandler(retry)
+
+	start := time.Now()
+	logger.Infow("msg 1")
+	logger.Flush()
+
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("\nwant:\nRetry-After bounded by MaxBackoff\nhave:\n%s", d)
+	}
+
+	if msgs := []string{"msg 1"}; !reflect.DeepEqual(flaky.msgs, msgs) {
+		t.Errorf("\nwant:\n%v\nhave:\n%v", msgs, flaky.msgs)
+	}
+}
+
+func TestRetryHandlerBreaker(t *testing.T) {
+	flaky := &flakyHandler{}
+	for i := 0; i < 10; i++ {
+		flaky.errs = append(flaky.errs, errors.New("connection refused"))
+	}
+
+	retry := golog.NewRetry(flaky, &golog.RetryOptions{
+		MaxAttempts:     2,
+		InitialBackoff:  time.Millisecond,
+		Threshold:       2,
+		BreakerInterval: time.Hour,
+	})
+	defer retry.Close()
+
+	logger := golog.NewLogger()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHandler(retry)
+
+	logger.Infow("msg 1")
+	logger.Flush()
+	logger.Infow("msg 2")
+	logger.Flush()
+
+	have := retry.Stats()
+	want := golog.RetryStats{State: "open", Retries: 1, Dropped: 1, Rejected: 1, LastError: "connection refused"}
+	if have != want {
+		t.Errorf("\nwant:\n%+v\nhave:\n%+v", want, have)
+	}
+
+	if len(flaky.calls) != 2 {
+		t.Errorf("\nwant:\n2 calls\nhave:\n%d", len(flaky.calls))
+	}
+
+	stats := logger.GetHandlerStats()
+	if len(stats) != 1 || stats[0].Errors != 1 {
+		t.Errorf("\nwant:\n1 handler error\nhave:\n%+v", stats)
+	}
+}
@@ -0,0 +1,197 @@
+package golog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Predicate returns if a message log must be forwarded by Filter
+type Predicate func(level int, fields Fields) bool
+
+// HandlerErrors aggregates errors returned by several handlers
+type HandlerErrors []error
+
+type levelRouter struct {
+	routes map[int]Handler
+}
+
+type filter struct {
+	predicate Predicate
+	handler   Handler
+}
+
+type tee struct {
+	handlers []Handler
+}
+
+func (e HandlerErrors) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// LevelRouter returns a handler forwarding each message log to the handler
+// of its level. Message logs of levels without handler are dropped.
+func LevelRouter(routes map[int]Handler) Handler {
+	r := &levelRouter{routes: make(map[int]Handler)}
+	for level, h := range routes {
+		r.routes[level] = h
+	}
+
+	return r
+}
+
+// PrintMsg forwards message log to the handler of its level
+func (r *levelRouter) PrintMsg(p int, l *Logger, level int, fields Fields) error {
+	if h, ok := r.routes[level]; ok && h != nil {
+		return h.PrintMsg(p, l, level, fields)
+	}
+
+	return nil
+}
+
+// Flush flushes handlers of all levels implementing Flusher
+func (r *levelRouter) Flush() error {
+	var handlers []Handler
+	for level := FATAL; level <= DEBUG; level++ {
+		if h, ok := r.routes[level]; ok && h != nil {
+			handlers = append(handlers, h)
+		}
+	}
+
+	return flushHandlers(handlers)
+}
+
+// Filter returns a handler forwarding to h only message logs matching predicate:
+//
+//	golog.Filter(golog.And(golog.MinLevel(golog.ERROR), golog.HasField("component", "payments")), slackHandler)
+func Filter(predicate Predicate, h Handler) Handler {
+	return &filter{predicate: predicate, handler: h}
+}
+
+// PrintMsg forwards message log if it matches the predicate
+func (f *filter) PrintMsg(p int, l *Logger, level int, fields Fields) error {
+	if !f.predicate(level, fields) {
+		return nil
+	}
+
+	return f.handler.PrintMsg(p, l, level, fields)
+}
+
+// Flush flushes the handler if it implements Flusher
+func (f *filter) Flush() error {
+	return flushHandlers([]Handler{f.handler})
+}
+
+// Tee returns a handler forwarding each message log to all handlers.
+// Errors of handlers are returned as HandlerErrors.
+func Tee(handlers ...Handler) Handler {
+	return &tee{handlers: append([]Handler{}, handlers...)}
+}
+
+// PrintMsg forwards message log to all handlers
+func (t *tee) PrintMsg(p int, l *Logger, level int, fields Fields) error {
+	var errs HandlerErrors
+	for _, h := range t.handlers {
+		if err := h.PrintMsg(p, l, level, fields); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Flush flushes handlers implementing Flusher
+func (t *tee) Flush() error {
+	return flushHandlers(t.handlers)
+}
+
+// flushHandlers flushes handlers implementing Flusher.
+// Errors are returned as HandlerErrors.
+func flushHandlers(handlers []Handler) error {
+	var errs HandlerErrors
+	for _, h := range handlers {
+		if f, ok := h.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// MinLevel matches message logs at or above level (FATAL being the highest)
+func MinLevel(level int) Predicate {
+	return func(l int, fields Fields) bool {
+		return l <= level
+	}
+}
+
+// HasField matches message logs having the field key with value.
+// Quotes added to string values are ignored.
+func HasField(key, value string) Predicate {
+	return func(level int, fields Fields) bool {
+		f := getField(key, fields.Log)
+		return f != nil && unquoteValue(f.Value) == value
+	}
+}
+
+// MessageMatches matches message logs whose message matches re
+func MessageMatches(re *regexp.Regexp) Predicate {
+	return func(level int, fields Fields) bool {
+		f := getField("msg", fields.Log)
+		return f != nil && re.MatchString(f.Value)
+	}
+}
+
+// And matches message logs matching all predicates
+func And(predicates ...Predicate) Predicate {
+	return func(level int, fields Fields) bool {
+		for _, p := range predicates {
+			if !p(level, fields) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches message logs matching at least one predicate
+func Or(predicates ...Predicate) Predicate {
+	return func(level int, fields Fields) bool {
+		for _, p := range predicates {
+			if p(level, fields) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches message logs not matching predicate
+func Not(predicate Predicate) Predicate {
+	return func(level int, fields Fields) bool {
+		return !predicate(level, fields)
+	}
+}
+
+func unquoteValue(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+
+	return s
+}
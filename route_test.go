@@ -0,0 +1,95 @@
+package golog_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/uthng/golog"
+)
+
+func TestRouteHandlers(t *testing.T) {
+	errorHandler := &memoryHandler{prefix: "error:"}
+	infoHandler := &memoryHandler{prefix: "info:"}
+	paymentsHandler := &memoryHandler{prefix: "payments:"}
+	allHandler := &memoryHandler{prefix: "all:"}
+
+	logger := golog.NewLogger()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHandler(golog.Tee(
+		golog.LevelRouter(map[int]golog.Handler{
+			golog.ERROR: errorHandler,
+			golog.INFO:  infoHandler,
+		}),
+		golog.Filter(golog.And(golog.MinLevel(golog.ERROR), golog.HasField("component", "payments")), paymentsHandler),
+		golog.Filter(golog.Not(golog.MessageMatches(regexp.MustCompile("^health"))), allHandler),
+	))
+
+	logger.Infow("health check")
+	logger.Infow("user logged in", "component", "auth")
+	logger.Warnw("card expiring", "component", "payments")
+	logger.Errorw("charge failed", "component", "payments")
+	logger.With("component", "auth").Errorw("login failed")
+
+	tests := []struct {
+		handler *memoryHandler
+		msgs    []string
+	}{
+		{errorHandler, []string{"error:charge failed", "error:login failed"}},
+		{infoHandler, []string{"info:health check", "info:user logged in"}},
+		{paymentsHandler, []string{"payments:charge failed"}},
+		{allHandler, []string{"all:user logged in", "all:card expiring", "all:charge failed", "all:login failed"}},
+	}
+
+	for _, tc := range tests {
+		if !reflect.DeepEqual(tc.handler.msgs, tc.msgs) {
+			t.Errorf("\nwant:\n%v\nhave:\n%v", tc.msgs, tc.handler.msgs)
+		}
+	}
+}
+
+func TestTeeErrors(t *testing.T) {
+	h := golog.Tee(errHandler{err: errors.New("error 1")}, &memoryHandler{}, errHandler{err: errors.New("error 2")})
+
+	err := h.PrintMsg(golog.PRINTW, golog.NewLogger(), golog.INFO, golog.Fields{Log: []*golog.Field{{Key: "msg", Value: "test"}}})
+	if err == nil || err.Error() != "error 1; error 2" {
+		t.Errorf("\nwant:\nerror 1; error 2\nhave:\n%v", err)
+	}
+}
+
+type errHandler struct {
+	err error
+}
+
+func (h errHandler) PrintMsg(p int, l *golog.Logger, level int, fields golog.Fields) error {
+	return h.err
+}
+
+// flushHandler counts calls to Flush
+type flushHandler struct {
+	memoryHandler
+	flushes int
+}
+
+func (h *flushHandler) Flush() error {
+	h.flushes++
+	return nil
+}
+
+func TestRouteFlush(t *testing.T) {
+	routed := &flushHandler{}
+	filtered := &flushHandler{}
+
+	logger := golog.NewLogger()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHandler(golog.LevelRouter(map[int]golog.Handler{golog.ERROR: routed, golog.INFO: &memoryHandler{}}))
+	logger.AddHandler(golog.Filter(golog.MinLevel(golog.ERROR), filtered))
+
+	logger.Flush()
+
+	if routed.flushes != 1 || filtered.flushes != 1 {
+		t.Errorf("\nwant:\n1 flush per handler\nhave:\n%d %d", routed.flushes, filtered.flushes)
+	}
+}
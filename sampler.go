@@ -0,0 +1,111 @@
+package golog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sampler limits message logs having the same level and call site.
+// In each interval, the first occurrences of a message log are logged
+// then only every thereafter-th one. Others are suppressed.
+type Sampler struct {
+	interval   time.Duration
+	first      int
+	thereafter int
+
+	tick       time.Time
+	counters   map[sampleKey]*sampleCounter
+	suppressed uint64
+
+	mutex sync.Mutex
+}
+
+// sampleKey identifies similar message logs without formatting them
+type sampleKey struct {
+	file  string
+	line  int
+	level int
+}
+
+type sampleCounter struct {
+	count   int
+	dropped int
+}
+
+// NewSampler returns a sampler logging the first n occurrences of
+// a message log per interval and then every m-th one. If m <= 0, all
+// occurrences after the first n are suppressed until next interval.
+func NewSampler(interval time.Duration, n, m int) *Sampler {
+	return &Sampler{
+		interval:   interval,
+		first:      n,
+		thereafter: m,
+		counters:   make(map[sampleKey]*sampleCounter),
+	}
+}
+
+// Suppressed returns the total number of message logs suppressed
+func (s *Sampler) Suppressed() uint64 {
+	return atomic.LoadUint64(&s.suppressed)
+}
+
+// check returns if the message log of the call site must be logged and
+// the number of its occurrences suppressed since the last time it was logged
+func (s *Sampler) check(level int, site *callSite, now time.Time) (bool, int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.Sub(s.tick) >= s.interval {
+		s.tick = now
+		s.counters = make(map[sampleKey]*sampleCounter)
+	}
+
+	key := sampleKey{file: site.file, line: site.line, level: level}
+	c, ok := s.counters[key]
+	if !ok {
+		c = &sampleCounter{}
+		s.counters[key] = c
+	}
+
+	c.count++
+	if c.count <= s.first || (s.thereafter > 0 && (c.count-s.first)%s.thereafter == 0) {
+		dropped := c.dropped
+		c.dropped = 0
+		return true, dropped
+	}
+
+	c.dropped++
+	atomic.AddUint64(&s.suppressed, 1)
+
+	return false, 0
+}
+
+// SetSampler sets the sampler for all levels. If s is nil,
+// sampling is disabled.
+func (l *Logger) SetSampler(s *Sampler) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for i := FATAL; i <= DEBUG; i++ {
+		l.levels[i].sampler = s
+	}
+}
+
+// SetLevelSampler sets the sampler for a specific level
+func (l *Logger) SetLevelSampler(level int, s *Sampler) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	l.levels[level].sampler = s
+}
+
+// SetSampler sets the sampler for all levels of the default logger
+func SetSampler(s *Sampler) {
+	defaultLogger.SetSampler(s)
+}
+
+// SetLevelSampler sets the sampler for a specific level of the default logger
+func SetLevelSampler(level int, s *Sampler) {
+	defaultLogger.SetLevelSampler(level, s)
+}
@@ -0,0 +1,97 @@
+package golog_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+func TestSampler(t *testing.T) {
+	var buf bytes.Buffer
+
+	clock := &manualClock{now: golog.DeterministicTime}
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetVerbosity(golog.DEBUG)
+	logger.SetClock(clock)
+
+	sampler := golog.NewSampler(time.Second, 2, 3)
+	logger.SetLevelSampler(golog.DEBUG, sampler)
+
+	for i := 0; i < 10; i++ {
+		logger.Debugw("In loop", "i", i)
+		logger.Infow("In loop", "i", i)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	logger.Debugw("In loop", "i", 10)
+
+	output := []string{
+		`DEBUG:[ ]+In loop[ ]+i=0$`,
+		`DEBUG:[ ]+In loop[ ]+i=1$`,
+		`DEBUG:[ ]+In loop[ ]+i=4 suppressed=2$`,
+		`DEBUG:[ ]+In loop[ ]+i=7 suppressed=2$`,
+		`DEBUG:[ ]+In loop[ ]+i=10$`,
+	}
+
+	var lines []string
+	for _, line := range strings.Split(utils.StringStripAnsi(buf.String()), "\n") {
+		if strings.Contains(line, "DEBUG") {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) != len(output) {
+		t.Fatalf("\nwant:\n%d debug lines\nhave:\n%s", len(output), strings.Join(lines, "\n"))
+	}
+
+	for idx, w := range output {
+		matched, _ := regexp.MatchString(w, lines[idx])
+		if !matched {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", w, lines[idx])
+		}
+	}
+
+	if n := strings.Count(buf.String(), "INFO"); n != 10 {
+		t.Errorf("\nwant:\n10 info lines\nhave:\n%d", n)
+	}
+
+	if sampler.Suppressed() != 6 {
+		t.Errorf("\nwant:\n6 suppressed\nhave:\n%d", sampler.Suppressed())
+	}
+}
+
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func TestSamplerCallSite(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.DisableColor()
+	logger.SetSampler(golog.NewSampler(time.Minute, 1, 0))
+
+	// Same call site whatever the arguments
+	for i := 0; i < 3; i++ {
+		logger.Info("Retry ", i)
+	}
+
+	// Same message at another call site
+	logger.Info("Retry ", 0)
+
+	output := "INFO:             Retry 0INFO:             Retry 0"
+	if buf.String() != output {
+		t.Errorf("\nwant:\n%q\nhave:\n%q", output, buf.String())
+	}
+}
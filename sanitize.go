@@ -0,0 +1,120 @@
+package golog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// SANITIZENONE writes user content verbatim
+	SANITIZENONE = iota
+	// SANITIZEESCAPE escapes control characters and ANSI sequences
+	SANITIZEESCAPE
+	// SANITIZESTRIP removes control characters and ANSI sequences
+	SANITIZESTRIP
+)
+
+const (
+	// MULTILINEESCAPE escapes line breaks as \n and \r
+	MULTILINEESCAPE = iota
+	// MULTILINEINDENT keeps line breaks but indents continuation lines
+	// with MultilineIndent so they cannot be taken for new message logs
+	MULTILINEINDENT
+	// MULTILINEKEEP keeps line breaks as they are
+	MULTILINEKEEP
+)
+
+// MultilineIndent prefixes continuation lines with MULTILINEINDENT policy
+var MultilineIndent = "    | "
+
+var ansiRegexp = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b[@-Z\\-_]`)
+
+type sanitizer struct {
+	mode      int
+	multiline int
+}
+
+// SetSanitization protects outputs against log injection by escaping
+// or stripping, according to mode, control characters and ANSI sequences
+// in user content. Line breaks in messages are handled by multiline policy.
+// Golog coloring is not affected. SANITIZENONE disables sanitization.
+func (l *Logger) SetSanitization(mode int, multiline int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if mode == SANITIZENONE {
+		l.sanitizer = nil
+		return
+	}
+
+	l.sanitizer = &sanitizer{mode: mode, multiline: multiline}
+}
+
+// SetSanitization sets sanitization of the default logger
+func SetSanitization(mode int, multiline int) {
+	defaultLogger.SetSanitization(mode, multiline)
+}
+
+// sanitizeFields returns fields with sanitized keys and values. Keys are
+// always escaped on one line. Fields are copied when modified since they
+// may be shared.
+func (s *sanitizer) sanitizeFields(fields []*Field) []*Field {
+	sanitized := make([]*Field, 0, len(fields))
+
+	for _, f := range fields {
+		k := s.sanitize(f.Key, MULTILINEESCAPE)
+		v := f.Value
+		if f.Key == "msg" {
+			// Trailing line break belongs to log format, not to content
+			trimmed := strings.TrimRight(v, "\n")
+			v = s.sanitize(trimmed, s.multiline) + v[len(trimmed):]
+		} else {
+			v = s.sanitize(v, MULTILINEESCAPE)
+		}
+
+		if k != f.Key || v != f.Value {
+			f = &Field{Key: k, Value: v}
+		}
+		sanitized = append(sanitized, f)
+	}
+
+	return sanitized
+}
+
+func (s *sanitizer) sanitize(str string, multiline int) string {
+	if s.mode == SANITIZESTRIP {
+		str = ansiRegexp.ReplaceAllString(str, "")
+	}
+
+	var b strings.Builder
+	for _, r := range str {
+		switch {
+		case r == '\n' || r == '\r':
+			switch multiline {
+			case MULTILINEKEEP:
+				b.WriteRune(r)
+			case MULTILINEINDENT:
+				if r == '\n' {
+					b.WriteString("\n" + MultilineIndent)
+				}
+			default:
+				if r == '\n' {
+					b.WriteString(`\n`)
+				} else {
+					b.WriteString(`\r`)
+				}
+			}
+		case r == '\t':
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r <= 0x9f):
+			if s.mode == SANITIZEESCAPE {
+				b.WriteString(fmt.Sprintf(`\x%02x`, r))
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package golog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/uthng/golog"
+)
+
+func TestSanitization(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mode      int
+		multiline int
+		log       func(l *golog.Logger)
+		output    string
+	}{
+		{
+			"Disabled",
+			golog.SANITIZENONE,
+			golog.MULTILINEESCAPE,
+			func(l *golog.Logger) { l.Infof("user=%s\n", "bob\nINFO: forged") },
+			"INFO:             user=bob\nINFO: forged\n",
+		},
+		{
+			"EscapeNewline",
+			golog.SANITIZEESCAPE,
+			golog.MULTILINEESCAPE,
+			func(l *golog.Logger) { l.Infof("user=%s\n", "bob\r\nINFO: forged") },
+			"INFO:             user=bob\\r\\nINFO: forged\n",
+		},
+		{
+			"EscapeANSI",
+			golog.SANITIZEESCAPE,
+			golog.MULTILINEESCAPE,
+			func(l *golog.Logger) { l.Infoln("name=\x1b[2Jbob\x07") },
+			"INFO:             name=\\x1b[2Jbob\\x07\n\n",
+		},
+		{
+			"StripANSI",
+			golog.SANITIZESTRIP,
+			golog.MULTILINEESCAPE,
+			func(l *golog.Logger) { l.Infoln("name=\x1b[31mbob\x1b[0m\x00") },
+			"INFO:             name=bob\n\n",
+		},
+		{
+			"MultilineIndent",
+			golog.SANITIZEESCAPE,
+			golog.MULTILINEINDENT,
+			func(l *golog.Logger) { l.Infof("Stack:\n%s\n", "main.go:10\nmain.go:20") },
+			"INFO:             Stack:\n    | main.go:10\n    | main.go:20\n",
+		},
+		{
+			"MultilineKeep",
+			golog.SANITIZEESCAPE,
+			golog.MULTILINEKEEP,
+			func(l *golog.Logger) { l.Infof("Stack:\n%s\n", "main.go:10\x1b[0m") },
+			"INFO:             Stack:\nmain.go:10\\x1b[0m\n",
+		},
+		{
+			"EscapeKey",
+			golog.SANITIZEESCAPE,
+			golog.MULTILINEKEEP,
+			func(l *golog.Logger) { l.Infow("x", "k\nERROR: forged=1", "v") },
+			"INFO:             x" + strings.Repeat(" ", 60) + "k\\nERROR: forged=1=\"v\"\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			logger := golog.NewLogger()
+			logger.SetOutput(&buf)
+			logger.DisableColor()
+			logger.SetSanitization(tc.mode, tc.multiline)
+
+			tc.log(logger)
+
+			if buf.String() != tc.output {
+				t.Errorf("\nwant:\n%q\nhave:\n%q", tc.output, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package golog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"strings"
+)
+
+const (
+	// SCRUBREDACT replaces a match with the rule name, ex: [EMAIL]
+	SCRUBREDACT = iota
+	// SCRUBPARTIAL masks a match except its last 4 characters
+	SCRUBPARTIAL
+	// SCRUBHASH replaces a match with its sha256 hash
+	SCRUBHASH
+)
+
+// ScrubRule defines a pattern of sensitive data to scrub
+// from message and field values
+type ScrubRule struct {
+	// Name identifies the rule and is used by SCRUBREDACT
+	Name string
+	// Pattern matches candidate data
+	Pattern *regexp.Regexp
+	// Validate filters out false positives if not nil
+	Validate func(match string) bool
+	// Strategy is the way a match is replaced
+	Strategy int
+}
+
+// Predefined scrub rules
+var (
+	ScrubEmail = ScrubRule{
+		Name:     "email",
+		Pattern:  regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		Strategy: SCRUBREDACT,
+	}
+	ScrubCreditCard = ScrubRule{
+		Name:     "credit_card",
+		Pattern:  regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Validate: luhn,
+		Strategy: SCRUBPARTIAL,
+	}
+	ScrubIPv4 = ScrubRule{
+		Name:     "ipv4",
+		Pattern:  regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`),
+		Strategy: SCRUBREDACT,
+	}
+	ScrubIPv6 = ScrubRule{
+		Name:     "ipv6",
+		Pattern:  regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{1,4}`),
+		Validate: isIPv6,
+		Strategy: SCRUBREDACT,
+	}
+	ScrubJWT = ScrubRule{
+		Name:     "jwt",
+		Pattern:  regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		Strategy: SCRUBREDACT,
+	}
+	ScrubAWSKey = ScrubRule{
+		Name:     "aws_key",
+		Pattern:  regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+		Strategy: SCRUBREDACT,
+	}
+)
+
+// DefaultScrubRules contains all predefined scrub rules
+var DefaultScrubRules = []ScrubRule{
+	ScrubEmail,
+	ScrubCreditCard,
+	ScrubJWT,
+	ScrubAWSKey,
+	ScrubIPv4,
+	ScrubIPv6,
+}
+
+type scrubber struct {
+	rules []ScrubRule
+}
+
+// SetScrubber enables scrubbing of message and field values with
+// the given rules before message logs are printed or sent to handlers.
+// Without rule, scrubbing is disabled.
+func (l *Logger) SetScrubber(rules ...ScrubRule) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if len(rules) == 0 {
+		l.scrubber = nil
+		return
+	}
+
+	l.scrubber = &scrubber{rules: append([]ScrubRule{}, rules...)}
+}
+
+// SetScrubber enables scrubbing for the default logger
+func SetScrubber(rules ...ScrubRule) {
+	defaultLogger.SetScrubber(rules...)
+}
+
+// scrubFields returns fields with scrubbed values.
+// Fields are copied when modified since they may be shared.
+func (s *scrubber) scrubFields(fields []*Field) []*Field {
+	scrubbed := make([]*Field, 0, len(fields))
+
+	for _, f := range fields {
+		if v := s.scrub(f.Value); v != f.Value {
+			f = &Field{Key: f.Key, Value: v}
+		}
+		scrubbed = append(scrubbed, f)
+	}
+
+	return scrubbed
+}
+
+// scrub replaces all matches of rules in str
+func (s *scrubber) scrub(str string) string {
+	for _, r := range s.rules {
+		rule := r
+		str = rule.Pattern.ReplaceAllStringFunc(str, func(m string) string {
+			if rule.Validate != nil && !rule.Validate(m) {
+				return m
+			}
+
+			return rule.replace(m)
+		})
+	}
+
+	return str
+}
+
+func (r ScrubRule) replace(m string) string {
+	switch r.Strategy {
+	case SCRUBPARTIAL:
+		if len(m) <= 4 {
+			return strings.Repeat("*", len(m))
+		}
+		return strings.Repeat("*", len(m)-4) + m[len(m)-4:]
+	case SCRUBHASH:
+		sum := sha256.Sum256([]byte(m))
+		return "sha256:" + hex.EncodeToString(sum[:])[:16]
+	default:
+		return "[" + strings.ToUpper(r.Name) + "]"
+	}
+}
+
+// luhn checks a credit card number with Luhn algorithm
+func luhn(s string) bool {
+	var digits []int
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits = append(digits, int(c-'0'))
+		}
+	}
+
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if (len(digits)-1-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return sum%10 == 0
+}
+
+func isIPv6(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && strings.Contains(s, ":")
+}
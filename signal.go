@@ -0,0 +1,77 @@
+package golog
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// HandleVerbositySignals installs signal handlers changing verbosity
+// at runtime: SIGUSR1 increases it by one level and SIGUSR2 decreases it,
+// within NONE and DEBUG. An INFO message log is printed at each change,
+// even if the new verbosity is lower than INFO.
+// The returned function uninstalls the handlers.
+// On platforms without SIGUSR1/SIGUSR2, it does nothing.
+func (l *Logger) HandleVerbositySignals() func() {
+	up, down := verbositySignals()
+	if up == nil || down == nil {
+		return func() {}
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	signal.Notify(signals, up, down)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				previous := l.GetVerbosity()
+				v := previous
+				if sig == up && v < DEBUG {
+					v++
+				} else if sig == down && v > NONE {
+					v--
+				}
+
+				if v == previous {
+					continue
+				}
+
+				l.SetVerbosity(v)
+				l.notice("Logger verbosity changed", "signal", sig.String(), "previous", levelName(previous), "verbosity", levelName(v))
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// HandleVerbositySignals installs signal handlers changing
+// verbosity of the default logger
+func HandleVerbositySignals() func() {
+	return defaultLogger.HandleVerbositySignals()
+}
+
+// notice logs an INFO message log whatever the verbosity of the logger
+// so that changes of verbosity are always reported
+func (l *Logger) notice(msg string, kv ...interface{}) {
+	child := l.With()
+	child.minLevel = INFO
+
+	logDepth(1, PRINTW, child, INFO, msg, kv...)
+}
@@ -0,0 +1,16 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package golog
+
+import (
+	"os"
+)
+
+func verbositySignals() (os.Signal, os.Signal) {
+	return nil, nil
+}
+
+func reloadSignal() os.Signal {
+	return nil
+}
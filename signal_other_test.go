@@ -0,0 +1,13 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package golog_test
+
+import (
+	"github.com/uthng/golog"
+)
+
+// sendReloadSignal reloads directly on platforms without SIGHUP
+func sendReloadSignal(w *golog.ConfigWatcher) {
+	w.Reload()
+}
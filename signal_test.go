@@ -0,0 +1,66 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package golog_test
+
+import (
+	"os"
+	"regexp"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+func TestHandleVerbositySignals(t *testing.T) {
+	var buf syncBuffer
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetVerbosity(golog.INFO)
+
+	uninstall := logger.HandleVerbositySignals()
+
+	p, _ := os.FindProcess(os.Getpid())
+	send := func(sig os.Signal, want int) {
+		p.Signal(sig)
+		for i := 0; i < 100 && logger.GetVerbosity() != want; i++ {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if logger.GetVerbosity() != want {
+			t.Fatalf("\nwant:\n%d\nhave:\n%d", want, logger.GetVerbosity())
+		}
+	}
+
+	send(syscall.SIGUSR1, golog.DEBUG)
+	send(syscall.SIGUSR2, golog.INFO)
+	send(syscall.SIGUSR2, golog.WARN)
+
+	outputs := []string{
+		`INFO:[ ]+Logger verbosity changed[ ]+signal="user defined signal 1" previous="INFO" verbosity="DEBUG"`,
+		// Printed even if verbosity is lowered below INFO
+		`INFO:[ ]+Logger verbosity changed[ ]+signal="user defined signal 2" previous="INFO" verbosity="WARN"`,
+	}
+	for _, output := range outputs {
+		// Message log is printed after verbosity is changed
+		matched := false
+		for i := 0; i < 100 && !matched; i++ {
+			matched, _ = regexp.MatchString(output, utils.StringStripAnsi(buf.String()))
+			time.Sleep(5 * time.Millisecond)
+		}
+		if !matched {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", output, buf.String())
+		}
+	}
+
+	// Uninstalling twice is harmless
+	uninstall()
+	uninstall()
+}
+
+func sendReloadSignal(w *golog.ConfigWatcher) {
+	p, _ := os.FindProcess(os.Getpid())
+	p.Signal(syscall.SIGHUP)
+}
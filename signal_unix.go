@@ -0,0 +1,17 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package golog
+
+import (
+	"os"
+	"syscall"
+)
+
+func verbositySignals() (os.Signal, os.Signal) {
+	return syscall.SIGUSR1, syscall.SIGUSR2
+}
+
+func reloadSignal() os.Signal {
+	return syscall.SIGHUP
+}
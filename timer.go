@@ -0,0 +1,112 @@
+package golog
+
+import (
+	"sync"
+	"time"
+)
+
+// Timer measures the duration of an operation and logs it
+// when the operation stops. It is obtained with StartTimer.
+type Timer struct {
+	logger    *Logger
+	name      string
+	kv        []interface{}
+	start     time.Time
+	threshold time.Duration
+	stopped   bool
+	duration  time.Duration
+	mutex     sync.Mutex
+}
+
+// StartTimer starts a timer for the operation name. The given key/value
+// pairs are added to the record logged when the timer stops:
+//
+//	t := logger.StartTimer("sync users", "source", "ldap")
+//	defer t.Stop()
+func (l *Logger) StartTimer(name string, kv ...interface{}) *Timer {
+	return &Timer{
+		logger: l,
+		name:   name,
+		kv:     append([]interface{}{}, kv...),
+		start:  l.clock.Now(),
+	}
+}
+
+// StartTimer starts a timer with the default logger
+func StartTimer(name string, kv ...interface{}) *Timer {
+	return defaultLogger.StartTimer(name, kv...)
+}
+
+// SetThreshold sets the duration above which the record is escalated to WARN
+func (t *Timer) SetThreshold(d time.Duration) *Timer {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.threshold = d
+
+	return t
+}
+
+// LogStart logs with info level that the operation has started
+func (t *Timer) LogStart() *Timer {
+	t.mutex.Lock()
+	kv := append([]interface{}{}, t.kv...)
+	t.mutex.Unlock()
+
+	logDepth(1, PRINTW, t.logger, INFO, t.name+" started", kv...)
+
+	return t
+}
+
+// With adds key/value pairs to the record logged when the timer stops
+func (t *Timer) With(kv ...interface{}) *Timer {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.kv = append(t.kv, kv...)
+
+	return t
+}
+
+// Stop stops the timer and logs the operation as successful.
+// It returns the duration of the operation. Only the first call logs.
+func (t *Timer) Stop() time.Duration {
+	return t.stop(nil)
+}
+
+// StopErr stops the timer and logs the operation with error level
+// if err is not nil. It returns the duration of the operation.
+func (t *Timer) StopErr(err error) time.Duration {
+	return t.stop(err)
+}
+
+func (t *Timer) stop(err error) time.Duration {
+	t.mutex.Lock()
+	if t.stopped {
+		t.mutex.Unlock()
+		return t.duration
+	}
+	t.stopped = true
+	t.duration = t.logger.clock.Now().Sub(t.start)
+
+	kv := append(append([]interface{}{}, t.kv...), "duration", t.duration)
+	level := INFO
+	if err != nil {
+		level = ERROR
+		kv = append(kv, "outcome", "error", "err", err)
+	} else {
+		kv = append(kv, "outcome", "success")
+	}
+
+	if t.threshold > 0 && t.duration > t.threshold {
+		if level > WARN {
+			level = WARN
+		}
+		kv = append(kv, "threshold", t.threshold)
+	}
+	t.mutex.Unlock()
+
+	logDepth(2, PRINTW, t.logger, level, t.name, kv...)
+
+	return t.duration
+}
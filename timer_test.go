@@ -0,0 +1,58 @@
+package golog_test
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+func TestTimer(t *testing.T) {
+	var buf bytes.Buffer
+
+	clock := &manualClock{now: golog.DeterministicTime}
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetFlags(golog.FCALLER)
+	logger.SetClock(clock)
+
+	timer := logger.StartTimer("sync users", "source", "ldap").LogStart()
+	clock.now = clock.now.Add(2 * time.Second)
+	timer.With("users", 10)
+	if d := timer.Stop(); d != 2*time.Second {
+		t.Errorf("\nwant:\n%s\nhave:\n%s", 2*time.Second, d)
+	}
+	timer.Stop()
+
+	timer = logger.StartTimer("sync groups").SetThreshold(time.Second)
+	clock.now = clock.now.Add(2 * time.Second)
+	timer.Stop()
+
+	timer = logger.StartTimer("sync roles").SetThreshold(time.Second)
+	clock.now = clock.now.Add(2 * time.Second)
+	timer.StopErr(errors.New("timeout"))
+
+	output := []string{
+		`timer_test.go:24:TestTimer INFO:[ ]+sync users started[ ]+source="ldap"$`,
+		`timer_test.go:27:TestTimer INFO:[ ]+sync users[ ]+source="ldap" users=10 duration=2s outcome="success"$`,
+		`timer_test.go:34:TestTimer WARN:[ ]+sync groups[ ]+duration=2s outcome="success" threshold=1s$`,
+		`timer_test.go:38:TestTimer ERROR:[ ]+sync roles[ ]+duration=2s outcome="error" err=timeout threshold=1s$`,
+	}
+
+	lines := strings.Split(strings.TrimRight(utils.StringStripAnsi(buf.String()), "\n"), "\n")
+	if len(lines) != len(output) {
+		t.Fatalf("\nwant:\n%d lines\nhave:\n%s", len(output), buf.String())
+	}
+
+	for idx, w := range output {
+		matched, _ := regexp.MatchString(w, lines[idx])
+		if !matched {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", w, lines[idx])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package golog
+
+import (
+	"sync/atomic"
+)
+
+// Verbose logs at INFO level only if the V level of the logger
+// is high enough. It is obtained with V.
+type Verbose struct {
+	logger *Logger
+}
+
+// SetV sets the threshold of numeric verbosity used by V
+func (l *Logger) SetV(v int) {
+	atomic.StoreInt32(&l.vlevel, int32(v))
+}
+
+// GetV returns the threshold of numeric verbosity used by V
+func (l *Logger) GetV() int {
+	return int(atomic.LoadInt32(&l.vlevel))
+}
+
+// V returns a Verbose logging at INFO level if n <= V threshold
+// of the logger, glog style: l.V(2).Infof("..."). When disabled,
+// calls are cheap as nothing is formatted and no lock is taken.
+func (l *Logger) V(n int) Verbose {
+	if n <= int(atomic.LoadInt32(&l.vlevel)) {
+		return Verbose{logger: l}
+	}
+
+	return Verbose{}
+}
+
+// SetV sets the threshold of numeric verbosity of the default logger
+func SetV(v int) {
+	defaultLogger.SetV(v)
+}
+
+// GetV returns the threshold of numeric verbosity of the default logger
+func GetV() int {
+	return defaultLogger.GetV()
+}
+
+// V returns a Verbose for the default logger
+func V(n int) Verbose {
+	return defaultLogger.V(n)
+}
+
+// Enabled returns if the verbose logs
+func (v Verbose) Enabled() bool {
+	return v.logger != nil
+}
+
+// Info logs with info level if enabled
+func (v Verbose) Info(args ...interface{}) {
+	if v.logger != nil {
+		logDepth(1, PRINT, v.logger, INFO, "", args...)
+	}
+}
+
+// Infof logs with info level if enabled
+func (v Verbose) Infof(f string, args ...interface{}) {
+	if v.logger != nil {
+		logDepth(1, PRINTF, v.logger, INFO, f, args...)
+	}
+}
+
+// Infoln logs with info level if enabled
+func (v Verbose) Infoln(args ...interface{}) {
+	if v.logger != nil {
+		logDepth(1, PRINTLN, v.logger, INFO, "", args...)
+	}
+}
+
+// Infow logs with info level with structured log format if enabled
+func (v Verbose) Infow(msg string, args ...interface{}) {
+	if v.logger != nil {
+		logDepth(1, PRINTW, v.logger, INFO, msg, args...)
+	}
+}
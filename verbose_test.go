@@ -0,0 +1,64 @@
+package golog_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+func TestV(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := golog.NewLogger()
+	logger.SetOutput(&buf)
+	logger.SetFlags(golog.FCALLER)
+	logger.SetV(2)
+
+	logger.V(1).Infof("This is V%d log\n", 1)
+	logger.V(2).Infow("This is V2 log", "key", "value")
+	logger.V(3).Infoln("This is V3 log")
+
+	if !logger.V(2).Enabled() || logger.V(3).Enabled() {
+		t.Errorf("\nwant:\nV2 enabled and V3 disabled\nhave:\n%t %t", logger.V(2).Enabled(), logger.V(3).Enabled())
+	}
+
+	output := []string{
+		`verbose_test.go:21:TestV INFO:[ ]+This is V1 log$`,
+		`verbose_test.go:22:TestV INFO:[ ]+This is V2 log[ ]+key="value"$`,
+	}
+
+	lines := strings.Split(strings.TrimRight(utils.StringStripAnsi(buf.String()), "\n"), "\n")
+	if len(lines) != len(output) {
+		t.Fatalf("\nwant:\n%d lines\nhave:\n%s", len(output), buf.String())
+	}
+
+	for idx, w := range output {
+		matched, _ := regexp.MatchString(w, lines[idx])
+		if !matched {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", w, lines[idx])
+		}
+	}
+
+	// INFO level must be enabled too
+	buf.Reset()
+	logger.SetVerbosity(golog.WARN)
+	logger.V(1).Info("This is V1 log")
+	if buf.Len() != 0 {
+		t.Errorf("\nwant:\nno log\nhave:\n%s", buf.String())
+	}
+}
+
+func BenchmarkVDisabled(b *testing.B) {
+	logger := golog.NewLogger()
+	logger.SetV(1)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.V(2).Infow("This is V2 log", "key", "value")
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package golog
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// vmodule overrides verbosity for source files matching patterns
+type vmodule struct {
+	spec  string
+	rules []vmoduleRule
+	cache sync.Map // *callSite -> int (-1 if no rule matches)
+}
+
+type vmoduleRule struct {
+	pattern string
+	verbose int
+}
+
+// SetVModule sets verbosity per source file or package with a comma separated
+// list of pattern=level, ex: "db/*=5,http_server.go=4". A pattern without /
+// matches the file name with or without .go extension. A pattern with /
+// matches the last directories and file name of the source path. The first
+// matching rule wins. Level is a name or a number. An empty spec removes
+// all rules.
+func (l *Logger) SetVModule(spec string) error {
+	vm, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	l.vmodule = vm
+
+	return nil
+}
+
+// GetVModule returns the current vmodule rules
+func (l *Logger) GetVModule() string {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if l.vmodule == nil {
+		return ""
+	}
+
+	return l.vmodule.spec
+}
+
+// SetVModule sets verbosity per source file or package of the default logger
+func SetVModule(spec string) error {
+	return defaultLogger.SetVModule(spec)
+}
+
+// GetVModule returns the current vmodule rules of the default logger
+func GetVModule() string {
+	return defaultLogger.GetVModule()
+}
+
+func parseVModule(spec string) (*vmodule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	vm := &vmodule{spec: spec}
+	for _, r := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(r), "=")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid vmodule rule %q", r)
+		}
+
+		pattern := strings.TrimSpace(parts[0])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid vmodule pattern %q: %s", pattern, err)
+		}
+
+		verbose, err := ParseLevel(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid vmodule rule %q: %s", r, err)
+		}
+
+		vm.rules = append(vm.rules, vmoduleRule{pattern: pattern, verbose: verbose})
+	}
+
+	return vm, nil
+}
+
+// verbosity returns the verbosity of a call site.
+// Result is cached so that rules are matched once per call site.
+func (vm *vmodule) verbosity(site *callSite, verbose int) int {
+	if v, ok := vm.cache.Load(site); ok {
+		if v.(int) < 0 {
+			return verbose
+		}
+		return v.(int)
+	}
+
+	v := -1
+	for _, r := range vm.rules {
+		if r.match(site.file) {
+			v = r.verbose
+			break
+		}
+	}
+	vm.cache.Store(site, v)
+
+	if v < 0 {
+		return verbose
+	}
+
+	return v
+}
+
+func (r vmoduleRule) match(file string) bool {
+	if !strings.Contains(r.pattern, "/") {
+		base := path.Base(file)
+		if ok, _ := path.Match(r.pattern, base); ok {
+			return true
+		}
+		ok, _ := path.Match(r.pattern, strings.TrimSuffix(base, ".go"))
+		return ok
+	}
+
+	// Compare with the same number of trailing path elements
+	n := strings.Count(r.pattern, "/") + 1
+	elems := strings.Split(file, "/")
+	if len(elems) < n {
+		return false
+	}
+
+	ok, _ := path.Match(r.pattern, strings.Join(elems[len(elems)-n:], "/"))
+
+	return ok
+}
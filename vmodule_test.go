@@ -0,0 +1,96 @@
+package golog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/uthng/golog"
+	utils "github.com/uthng/goutils"
+)
+
+func TestVModule(t *testing.T) {
+	testCases := []struct {
+		name    string
+		verbose int
+		spec    string
+		output  []string
+	}{
+		{
+			"NoRule",
+			golog.INFO,
+			"",
+			[]string{"INFO", "WARN"},
+		},
+		{
+			"FileName",
+			golog.INFO,
+			"vmodule_test=debug",
+			[]string{"DEBUG", "INFO", "WARN"},
+		},
+		{
+			"FileNameWithExtension",
+			golog.DEBUG,
+			"vmodule_test.go=3",
+			[]string{"WARN"},
+		},
+		{
+			"Directory",
+			golog.INFO,
+			"other.go=none,*/vmodule_*.go=5",
+			[]string{"DEBUG", "INFO", "WARN"},
+		},
+		{
+			"NoMatch",
+			golog.INFO,
+			"db/*=debug",
+			[]string{"INFO", "WARN"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			logger := golog.NewLogger()
+			logger.SetOutput(&buf)
+			logger.SetVerbosity(tc.verbose)
+			if err := logger.SetVModule(tc.spec); err != nil {
+				t.Fatal(err)
+			}
+
+			// Log twice to use cached call sites
+			for i := 0; i < 2; i++ {
+				buf.Reset()
+				logger.Debugln("This is debug log")
+				logger.Infoln("This is info log")
+				logger.Warnln("This is warn log")
+
+				var levels []string
+				for _, line := range strings.Split(utils.StringStripAnsi(buf.String()), "\n") {
+					if f := strings.Fields(line); len(f) > 0 {
+						levels = append(levels, strings.TrimSuffix(f[0], ":"))
+					}
+				}
+
+				if strings.Join(levels, ",") != strings.Join(tc.output, ",") {
+					t.Errorf("\nwant:\n%v\nhave:\n%v", tc.output, levels)
+				}
+			}
+
+			if logger.GetVModule() != tc.spec {
+				t.Errorf("\nwant:\n%s\nhave:\n%s", tc.spec, logger.GetVModule())
+			}
+		})
+	}
+}
+
+func TestVModuleInvalid(t *testing.T) {
+	logger := golog.NewLogger()
+
+	for _, spec := range []string{"db", "db/*=trace", "[=5"} {
+		if err := logger.SetVModule(spec); err == nil {
+			t.Errorf("\nwant:\nerror for %q\nhave:\nnil", spec)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigWatcher reloads the configuration file of a logger
+// when the file changes or when SIGHUP is received
+type ConfigWatcher struct {
+	logger   *Logger
+	path     string
+	interval time.Duration
+
+	modTime time.Time
+	size    int64
+
+	signals chan os.Signal
+	done    chan struct{}
+	once    sync.Once
+	mutex   sync.Mutex
+}
+
+// WatchConfig applies the configuration file to the logger then watches it.
+// The file is checked for changes at each interval. When changed or when
+// SIGHUP is received, the new configuration replaces the current one
+// atomically if it is valid. Otherwise, the current one is kept.
+// If interval <= 0, the file is not checked and is only reloaded on
+// SIGHUP or by Reload. SIGHUP is only handled on unix platforms. Handlers added with AddHandler are kept across
+// reloads.
+func (l *Logger) WatchConfig(path string, interval time.Duration) (*ConfigWatcher, error) {
+	w := &ConfigWatcher{
+		logger:   l,
+		path:     path,
+		interval: interval,
+		signals:  make(chan os.Signal, 1),
+		done:     make(chan struct{}),
+	}
+
+	w.stat()
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cfg.build()
+	if err != nil {
+		return nil, err
+	}
+	l.apply(c)
+
+	if sig := reloadSignal(); sig != nil {
+		signal.Notify(w.signals, sig)
+	}
+	go w.watch()
+
+	return w, nil
+}
+
+// WatchConfig watches the configuration file of the default logger
+func WatchConfig(path string, interval time.Duration) (*ConfigWatcher, error) {
+	return defaultLogger.WatchConfig(path, interval)
+}
+
+// Reload reads the configuration file and applies it to the logger
+// if it is valid. An INFO message log describes what changed, whatever
+// the new verbosity.
+func (w *ConfigWatcher) Reload() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	l := w.logger
+
+	var c *config
+	cfg, err := LoadConfig(w.path)
+	if err == nil {
+		c, err = cfg.build()
+	}
+
+	if err != nil {
+		l.Errorw("Failed to reload logger config, keeping current one", "path", w.path, "err", err)
+		return err
+	}
+
+	changes := diffConfig(l.apply(c), c)
+	l.notice("Logger config reloaded", "path", w.path, "changes", strings.Join(changes, ", "))
+
+	return nil
+}
+
+// Close stops watching the configuration file
+func (w *ConfigWatcher) Close() {
+	w.once.Do(func() {
+		signal.Stop(w.signals)
+		close(w.done)
+	})
+}
+
+func (w *ConfigWatcher) watch() {
+	var tick <-chan time.Time
+	if w.interval > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.signals:
+			w.stat()
+			w.Reload()
+		case <-tick:
+			if w.stat() {
+				w.Reload()
+			}
+		}
+	}
+}
+
+// stat returns if the file has changed since last call
+func (w *ConfigWatcher) stat() bool {
+	fi, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+
+	changed := !fi.ModTime().Equal(w.modTime) || fi.Size() != w.size
+	w.modTime = fi.ModTime()
+	w.size = fi.Size()
+
+	return changed
+}
+
+// diffConfig describes the differences between 2 configurations
+func diffConfig(old, c *config) []string {
+	var changes []string
+
+	if old == nil {
+		return []string{"all"}
+	}
+
+	if old.verbose != c.verbose {
+		changes = append(changes, fmt.Sprintf("verbosity: %s -> %s", levelName(old.verbose), levelName(c.verbose)))
+	}
+
+	if old.flag != c.flag {
+		changes = append(changes, fmt.Sprintf("flags: %v -> %v", old.source.Flags, c.source.Flags))
+	}
+
+	if old.timeFormat != c.timeFormat {
+		changes = append(changes, fmt.Sprintf("time_format: %q -> %q", old.timeFormat, c.timeFormat))
+	}
+
+	if old.color != c.color {
+		changes = append(changes, fmt.Sprintf("color: %t -> %t", old.color, c.color))
+	}
+
+	if old.source.Format != c.source.Format {
+		changes = append(changes, fmt.Sprintf("format: %q -> %q", old.source.Format, c.source.Format))
+	}
+
+	if old.source.Output != c.source.Output || !reflect.DeepEqual(old.source.Outputs, c.source.Outputs) {
+		changes = append(changes, "outputs")
+	}
+
+	if !reflect.DeepEqual(old.source.Handlers, c.source.Handlers) {
+		changes = append(changes, fmt.Sprintf("handlers: %d -> %d", len(old.handlers), len(c.handlers)))
+	}
+
+	if len(changes) == 0 {
+		changes = append(changes, "none")
+	}
+
+	return changes
+}
+
+func levelName(level int) string {
+	if name, ok := prefixes[level]; ok {
+		return name
+	}
+
+	return "NONE"
+}
@@ -0,0 +1,113 @@
+package golog_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uthng/golog"
+)
+
+func TestWatchConfig(t *testing.T) {
+	path := writeConfig(t, "golog.yaml", "verbosity: info\ncolor: false\noutput: {dir}/golog.log\n")
+	output := filepath.Join(filepath.Dir(path), "golog.log")
+
+	logger := golog.NewLogger()
+	w, err := logger.WatchConfig(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Handlers added in code are kept across reloads
+	logger.AddHandler(&countHandler{})
+
+	update := func(content string, mtime time.Time) {
+		// Rename so that the watcher never reads a partially written file
+		content = strings.ReplaceAll(content, "{dir}", filepath.Dir(path))
+		if err := ioutil.WriteFile(path+".tmp", []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path+".tmp", mtime, mtime)
+		if err := os.Rename(path+".tmp", path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitVerbosity := func(v int) {
+		for i := 0; i < 100 && logger.GetVerbosity() != v; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if logger.GetVerbosity() != v {
+			t.Fatalf("\nwant:\n%d\nhave:\n%d", v, logger.GetVerbosity())
+		}
+	}
+
+	// Changed file
+	update("verbosity: debug\ncolor: false\noutput: {dir}/golog.log\n", time.Now().Add(time.Hour))
+	waitVerbosity(golog.DEBUG)
+
+	// Invalid file keeps current config
+	update("verbosity: verbose\n", time.Now().Add(2*time.Hour))
+	time.Sleep(100 * time.Millisecond)
+	if logger.GetVerbosity() != golog.DEBUG {
+		t.Errorf("\nwant:\n%d\nhave:\n%d", golog.DEBUG, logger.GetVerbosity())
+	}
+
+	// SIGHUP without file modification
+	update("verbosity: warn\ncolor: false\noutput: {dir}/golog.log\n", time.Now().Add(2*time.Hour))
+	sendReloadSignal(w)
+	waitVerbosity(golog.WARN)
+
+	if stats := logger.GetHandlerStats(); len(stats) != 1 || stats[0].Name != "*golog_test.countHandler" {
+		t.Errorf("\nwant:\nhandler added in code\nhave:\n%+v", stats)
+	}
+
+	outputs := []string{
+		`INFO:[ ]+Logger config reloaded[ ]+path=".*" changes="verbosity: INFO -> DEBUG"`,
+		`ERROR:[ ]+Failed to reload logger config, keeping current one[ ]+path=".*" err=.*unknown level "verbose"`,
+		`INFO:[ ]+Logger config reloaded[ ]+path=".*" changes="verbosity: DEBUG -> WARN"`,
+	}
+	for _, o := range outputs {
+		// Record of the last reload is printed once verbosity is set
+		var b []byte
+		for i := 0; i < 100; i++ {
+			if b, _ = ioutil.ReadFile(output); regexp.MustCompile(o).Match(b) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if !regexp.MustCompile(o).Match(b) {
+			t.Errorf("\nwant:\n%s\nhave:\n%s", o, b)
+		}
+	}
+}
+
+func TestWatchConfigNoInterval(t *testing.T) {
+	path := writeConfig(t, "golog.yaml", "verbosity: info\n")
+
+	logger := golog.NewLogger()
+	w, err := logger.WatchConfig(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// File is not checked for changes
+	ioutil.WriteFile(path, []byte("verbosity: error\n"), 0644)
+	time.Sleep(50 * time.Millisecond)
+	if logger.GetVerbosity() != golog.INFO {
+		t.Errorf("\nwant:\n%d\nhave:\n%d", golog.INFO, logger.GetVerbosity())
+	}
+
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if logger.GetVerbosity() != golog.ERROR {
+		t.Errorf("\nwant:\n%d\nhave:\n%d", golog.ERROR, logger.GetVerbosity())
+	}
+}